@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"auth-service/handlers"
+	"auth-service/logger"
+	"auth-service/metrics"
+	"auth-service/middleware"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// newAdminRouter создает роутер административного listener: административные эндпоинты,
+// метрики, pprof и Swagger UI. Все маршруты требуют аутентификации, заданной в admin_listener.auth.
+func newAdminRouter(appCtx *handlers.AppContext, accessLogger *logger.ColorfulLogger) *gin.Engine {
+	r := gin.New()
+	r.Use(
+		gin.Recovery(),
+		middleware.RequestID(),
+		middleware.AccessLog(appCtx, accessLogger),
+	)
+	r.NoRoute(func(c *gin.Context) { problem.Abort(c, problem.CodeNotFound) })
+
+	protected := r.Group("/", middleware.AdminAccess(appCtx, appCtx.Config().AdminListener.Auth))
+
+	// Метрики Prometheus, профилирование и документация API
+	protected.GET("/metrics", gin.WrapH(metrics.Handler()))
+	protected.Any("/debug/pprof/*profile", gin.WrapH(pprofHandler()))
+	protected.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Административные эндпоинты
+	protected.GET("/admin/log-level", handlers.GetLogLevel(appCtx))
+	protected.PUT("/admin/log-level", handlers.SetLogLevel(appCtx))
+	protected.GET("/admin/audit", handlers.QueryAudit(appCtx))
+
+	// Управление учетными записями пользователей
+	protected.GET("/admin/users", handlers.ListUsers(appCtx))
+	protected.GET("/admin/users/:username", handlers.GetUser(appCtx))
+	protected.DELETE("/admin/users/:username", handlers.DeleteUser(appCtx))
+	protected.POST("/admin/users/:username/disable", handlers.DisableUser(appCtx))
+	protected.POST("/admin/users/:username/enable", handlers.EnableUser(appCtx))
+	protected.POST("/admin/users/:username/password-reset", handlers.ForcePasswordReset(appCtx))
+
+	return r
+}
+
+// pprofHandler возвращает обработчики net/http/pprof без регистрации в http.DefaultServeMux
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
@@ -0,0 +1,209 @@
+// Файл: audit/audit.go
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Типы событий журнала аудита
+const (
+	EventLoginSuccess   = "login_success"
+	EventLoginFailure   = "login_failure"
+	EventTokenIssued    = "token_issued"
+	EventTokenRefreshed = "token_refreshed"
+	EventTokenRevoked   = "token_revoked"
+	EventLogout         = "logout"
+	EventAdminAction    = "admin_action"
+)
+
+// Результаты событий
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// genesisHash используется как prev_hash первой записи цепочки
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+// Event представляет запись журнала аудита
+// @Description Запись журнала аудита
+type Event struct {
+	Seq       uint64            `json:"seq" example:"1"`                         // Порядковый номер записи
+	Time      time.Time         `json:"time"`                                    // Время события
+	Type      string            `json:"type" example:"login_success"`            // Тип события
+	Outcome   string            `json:"outcome" example:"success"`               // Результат: success или failure
+	Username  string            `json:"username,omitempty" example:"user123"`    // Пользователь, к которому относится событие
+	AgencyID  int               `json:"agency_id,omitempty" example:"42"`        // ID агентства
+	Actor     string            `json:"actor,omitempty" example:"admin"`         // Кто выполнил действие, если не сам пользователь
+	RequestID string            `json:"request_id,omitempty"`                    // Идентификатор HTTP запроса
+	ClientIP  string            `json:"client_ip,omitempty" example:"10.0.0.1"`  // IP клиента
+	Reason    string            `json:"reason,omitempty" example:"bad_password"` // Причина отказа
+	Details   map[string]string `json:"details,omitempty"`                       // Дополнительные сведения
+	PrevHash  string            `json:"prev_hash"`                               // Хеш предыдущей записи
+	Hash      string            `json:"hash,omitempty"`                          // Хеш этой записи, включая prev_hash
+}
+
+// computeHash вычисляет хеш записи по ее содержимому без поля hash
+func computeHash(event Event) (string, error) {
+	event.Hash = ""
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Logger записывает события в журнал аудита, связывая записи цепочкой хешей
+type Logger struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	seq      uint64
+	lastHash string
+}
+
+// Open открывает журнал аудита для дозаписи и восстанавливает конец цепочки
+func Open(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог журнала аудита: %w", err)
+	}
+
+	l := &Logger{path: path, lastHash: genesisHash}
+
+	// Восстанавливаем номер и хеш последней записи
+	err := scan(path, func(event Event) error {
+		l.seq = event.Seq
+		l.lastHash = event.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("не удалось прочитать журнал аудита: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть журнал аудита: %w", err)
+	}
+	l.file = file
+
+	return l, nil
+}
+
+// Record дописывает событие в журнал и сбрасывает его на диск
+func (l *Logger) Record(event Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("журнал аудита закрыт")
+	}
+
+	event.Seq = l.seq + 1
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	event.PrevHash = l.lastHash
+
+	hash, err := computeHash(event)
+	if err != nil {
+		return fmt.Errorf("ошибка вычисления хеша записи аудита: %w", err)
+	}
+	event.Hash = hash
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("ошибка маршалинга записи аудита: %w", err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("ошибка записи в журнал аудита: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("ошибка сброса журнала аудита на диск: %w", err)
+	}
+
+	l.seq = event.Seq
+	l.lastHash = event.Hash
+	return nil
+}
+
+// Close закрывает файл журнала аудита
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// scan последовательно читает записи журнала
+func scan(path string, fn func(Event) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("строка %d: некорректная запись: %w", line, err)
+		}
+		if err := fn(event); err != nil {
+			return fmt.Errorf("строка %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Verify проверяет целостность цепочки хешей журнала и возвращает количество записей
+func Verify(path string) (int, error) {
+	count := 0
+	prevHash := genesisHash
+	var prevSeq uint64
+
+	err := scan(path, func(event Event) error {
+		if event.Seq != prevSeq+1 {
+			return fmt.Errorf("нарушена нумерация: ожидалась запись %d, получена %d", prevSeq+1, event.Seq)
+		}
+		if event.PrevHash != prevHash {
+			return fmt.Errorf("запись %d: prev_hash не совпадает с хешем предыдущей записи", event.Seq)
+		}
+		hash, err := computeHash(event)
+		if err != nil {
+			return err
+		}
+		if hash != event.Hash {
+			return fmt.Errorf("запись %d: хеш не совпадает с содержимым записи", event.Seq)
+		}
+
+		prevSeq = event.Seq
+		prevHash = event.Hash
+		count++
+		return nil
+	})
+
+	return count, err
+}
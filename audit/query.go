@@ -0,0 +1,74 @@
+// Файл: audit/query.go
+package audit
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Filter задает условия выборки записей журнала аудита
+type Filter struct {
+	Username string
+	AgencyID *int
+	Types    []string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// matches проверяет, удовлетворяет ли событие фильтру
+func (f Filter) matches(event Event) bool {
+	if f.Username != "" && event.Username != f.Username {
+		return false
+	}
+	if f.AgencyID != nil && event.AgencyID != *f.AgencyID {
+		return false
+	}
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if event.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !f.From.IsZero() && event.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && event.Time.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// Query возвращает последние записи журнала, удовлетворяющие фильтру, от новых к старым
+func (l *Logger) Query(filter Filter) ([]Event, error) {
+	// Блокируем запись, чтобы не прочитать частично записанную строку
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var events []Event
+	err := scan(l.path, func(event Event) error {
+		if filter.matches(event) {
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// Разворачиваем, чтобы новые записи шли первыми
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
@@ -2,36 +2,119 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"auth-service/config"
+	"auth-service/logger"
+	"auth-service/metrics"
 	"auth-service/models"
+	"auth-service/tracing"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Ошибки API, по которым обработчики выбирают код ответа
+var (
+	ErrUserNotFound = errors.New("пользователь не найден")
+	ErrUnavailable  = errors.New("API недоступен")
 )
 
 // APIClient предоставляет методы для взаимодействия с локальным API
 type APIClient struct {
 	BaseURL     string
+	HealthPath  string
 	ServiceName string
 	HTTPClient  *http.Client
+	Logger      *logger.ColorfulLogger
 }
 
 // NewAPIClient создает новый экземпляр клиента API
-func NewAPIClient(cfg *config.Config) *APIClient {
+func NewAPIClient(cfg *config.Config, log *logger.ColorfulLogger) *APIClient {
 	return &APIClient{
 		BaseURL:     cfg.LocalAPIURL,
+		HealthPath:  cfg.LocalAPIHealthPath,
 		ServiceName: cfg.ServiceName,
 		HTTPClient:  &http.Client{},
+		Logger:      log.Component(logger.ComponentClient),
+	}
+}
+
+// newRequest создает запрос к API, передавая идентификатор входящего запроса
+func (c *APIClient) newRequest(ctx context.Context, method, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if requestID := logger.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
 	}
+	return req, nil
+}
+
+// do выполняет запрос к API в отдельном клиентском спане, передавая traceparent,
+// и учитывает его длительность в метриках под именем endpoint
+func (c *APIClient) do(req *http.Request, endpoint string) (*http.Response, error) {
+	ctx, span := tracing.StartWithKind(req.Context(), "backend "+endpoint, trace.SpanKindClient,
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+		semconv.URLPath(req.URL.Path),
+	)
+	req = req.WithContext(ctx)
+	tracing.Inject(ctx, req.Header)
+
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+
+	status := "error"
+	spanErr := err
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			spanErr = fmt.Errorf("API вернул ошибку: %d", resp.StatusCode)
+		}
+	}
+	metrics.ObserveBackendRequest(endpoint, status, time.Since(start))
+	tracing.End(span, spanErr)
+
+	return resp, err
+}
+
+// Ping проверяет доступность API: любой ответ, кроме ошибки сервера, считается успешным
+func (c *APIClient) Ping(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodGet, c.BaseURL+c.HealthPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, "ping")
+	if err != nil {
+		return fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: API вернул ошибку: %d", ErrUnavailable, resp.StatusCode)
+	}
+	return nil
 }
 
 // GetUser получает данные пользователя из БД
-func (c *APIClient) GetUser(username string) (*models.UserData, error) {
-	url := fmt.Sprintf("%s/get_user_data/?username=%s", c.BaseURL, username)
+func (c *APIClient) GetUser(ctx context.Context, username string) (*models.UserData, error) {
+	log := c.Logger.WithContext(ctx).With(logger.Fields{"username": username})
+	// Имя экранируется: символы & и # в нем не должны менять запрос к БД
+	requestURL := fmt.Sprintf("%s/get_user_data/?%s", c.BaseURL, url.Values{"username": {username}}.Encode())
 
 	request := map[string]string{
 		"name": c.ServiceName,
@@ -42,20 +125,24 @@ func (c *APIClient) GetUser(username string) (*models.UserData, error) {
 		return nil, fmt.Errorf("ошибка маршалинга запроса: %w", err)
 	}
 
-	log.Printf("Request to %s with body %s", url, string(reqBody))
+	log.Debug("Запрос к %s с телом %s", requestURL, string(reqBody))
 
-	resp, err := c.HTTPClient.Post(url, "application/json", bytes.NewBuffer(reqBody))
+	req, err := c.newRequest(ctx, http.MethodPost, requestURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
 
+	resp, err := c.do(req, "get_user")
 	if err != nil {
-		log.Printf("ошибка сетевого запроса: %v", err)
-		return nil, fmt.Errorf("ошибка сетевого запроса: %w", err)
+		log.Error("Ошибка сетевого запроса: %v", err)
+		return nil, fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		log.Printf("API вернул ошибку: %d - %s", resp.StatusCode, string(bodyBytes))
-		return nil, fmt.Errorf("API вернул ошибку: %d - %s", resp.StatusCode, string(bodyBytes))
+		log.Error("API вернул ошибку: %d - %s", resp.StatusCode, string(bodyBytes))
+		return nil, statusError(resp.StatusCode, bodyBytes)
 	}
 
 	var response struct {
@@ -63,20 +150,20 @@ func (c *APIClient) GetUser(username string) (*models.UserData, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		log.Printf("ошибка декодирования ответа: %v", err)
+		log.Error("Ошибка декодирования ответа: %v", err)
 		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
 	}
 
 	if response.Data.Login == "" {
-		log.Printf("пользователь не найден. %v", response.Data)
-		return nil, errors.New("пользователь не найден")
+		log.Warn("Пользователь не найден")
+		return nil, ErrUserNotFound
 	}
 
 	return &response.Data, nil
 }
 
 // UpdateToken обновляет токен пользователя в БД
-func (c *APIClient) UpdateToken(username, token string) error {
+func (c *APIClient) UpdateToken(ctx context.Context, username, token string) error {
 	url := fmt.Sprintf("%s/token/update", c.BaseURL)
 
 	request := models.LocalAPIRequest{}
@@ -89,22 +176,60 @@ func (c *APIClient) UpdateToken(username, token string) error {
 		return fmt.Errorf("ошибка маршалинга запроса: %w", err)
 	}
 
-	resp, err := c.HTTPClient.Post(url, "application/json", bytes.NewBuffer(reqBody))
+	req, err := c.newRequest(ctx, http.MethodPost, url, reqBody)
 	if err != nil {
-		return fmt.Errorf("ошибка сетевого запроса: %w", err)
+		return err
+	}
+
+	resp, err := c.do(req, "update_token")
+	if err != nil {
+		return fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API вернул ошибку: %d - %s", resp.StatusCode, string(bodyBytes))
+		return statusError(resp.StatusCode, bodyBytes)
+	}
+
+	return nil
+}
+
+// UpdatePassword заменяет хеш пароля пользователя в БД
+func (c *APIClient) UpdatePassword(ctx context.Context, username, hash string) error {
+	url := fmt.Sprintf("%s/password/update", c.BaseURL)
+
+	request := models.UpdatePasswordRequest{}
+	request.MicroName.Name = c.ServiceName
+	request.PasswordData.Login = username
+	request.PasswordData.Password = hash
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("ошибка маршалинга запроса: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, url, reqBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, "update_password")
+	if err != nil {
+		return fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, bodyBytes)
 	}
 
 	return nil
 }
 
 // DeleteToken удаляет токен пользователя из БД
-func (c *APIClient) DeleteToken(username, token string) error {
+func (c *APIClient) DeleteToken(ctx context.Context, username, token string) error {
 	url := fmt.Sprintf("%s/token/delete", c.BaseURL)
 
 	request := models.LocalAPIRequest{}
@@ -117,22 +242,141 @@ func (c *APIClient) DeleteToken(username, token string) error {
 		return fmt.Errorf("ошибка маршалинга запроса: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodDelete, url, bytes.NewBuffer(reqBody))
+	req, err := c.newRequest(ctx, http.MethodDelete, url, reqBody)
 	if err != nil {
-		return fmt.Errorf("ошибка создания запроса: %w", err)
+		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req, "delete_token")
 	if err != nil {
-		return fmt.Errorf("ошибка сетевого запроса: %w", err)
+		return fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API вернул ошибку: %d - %s", resp.StatusCode, string(bodyBytes))
+		return statusError(resp.StatusCode, bodyBytes)
 	}
 
 	return nil
 }
+
+// ListUsers возвращает страницу пользователей агентства agencyID (nil - всех агентств),
+// логин которых содержит search, и общее число пользователей по фильтру
+func (c *APIClient) ListUsers(ctx context.Context, agencyID *int, search string, offset, limit int) ([]models.UserData, int, error) {
+	url := fmt.Sprintf("%s/users/list", c.BaseURL)
+
+	request := models.ListUsersRequest{}
+	request.MicroName.Name = c.ServiceName
+	request.Filter.AgencyID = agencyID
+	request.Filter.Search = search
+	request.Filter.Offset = offset
+	request.Filter.Limit = limit
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка маршалинга запроса: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, url, reqBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := c.do(req, "list_users")
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, 0, statusError(resp.StatusCode, bodyBytes)
+	}
+
+	var response struct {
+		Data  []models.UserData `json:"data"`
+		Total int               `json:"total"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, 0, fmt.Errorf("ошибка декодирования ответа: %w", err)
+	}
+
+	return response.Data, response.Total, nil
+}
+
+// UpdateUser изменяет признаки учетной записи пользователя в БД
+func (c *APIClient) UpdateUser(ctx context.Context, username string, status models.UserStatus) error {
+	url := fmt.Sprintf("%s/user/update", c.BaseURL)
+
+	request := models.UpdateUserRequest{}
+	request.MicroName.Name = c.ServiceName
+	request.UserData.Login = username
+	request.UserData.UserStatus = status
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("ошибка маршалинга запроса: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, url, reqBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, "update_user")
+	if err != nil {
+		return fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, bodyBytes)
+	}
+
+	return nil
+}
+
+// DeleteUser удаляет пользователя из БД
+func (c *APIClient) DeleteUser(ctx context.Context, username string) error {
+	url := fmt.Sprintf("%s/user/delete", c.BaseURL)
+
+	request := models.DeleteUserRequest{}
+	request.MicroName.Name = c.ServiceName
+	request.UserData.Login = username
+
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("ошибка маршалинга запроса: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodDelete, url, reqBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, "delete_user")
+	if err != nil {
+		return fmt.Errorf("%w: ошибка сетевого запроса: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return statusError(resp.StatusCode, bodyBytes)
+	}
+
+	return nil
+}
+
+// statusError описывает неуспешный ответ API; ошибки сервера считаются недоступностью API
+func statusError(status int, body []byte) error {
+	if status == http.StatusNotFound {
+		return fmt.Errorf("%w: API вернул ошибку: %d - %s", ErrUserNotFound, status, string(body))
+	}
+	if status >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: API вернул ошибку: %d - %s", ErrUnavailable, status, string(body))
+	}
+	return fmt.Errorf("API вернул ошибку: %d - %s", status, string(body))
+}
@@ -0,0 +1,47 @@
+// Файл: client/api_client_test.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"auth-service/config"
+	"auth-service/logger"
+	"auth-service/models"
+)
+
+func TestGetUserEscapesUsername(t *testing.T) {
+	usernames := []string{"user123", "a&username=b", "a#b", "a b+c", "a%2Fb", "имя?x=1"}
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query()["username"]; len(got) == 1 {
+			received = append(received, got[0])
+		} else {
+			received = append(received, "")
+		}
+		json.NewEncoder(w).Encode(map[string]models.UserData{"data": {Login: r.URL.Query().Get("username")}})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{LocalAPIURL: server.URL, ServiceName: "auth-service", LogLevel: "error"}
+	log, err := logger.NewColorfulLogger(cfg)
+	if err != nil {
+		t.Fatalf("NewColorfulLogger: %v", err)
+	}
+	apiClient := NewAPIClient(cfg, log.WithWriter(io.Discard))
+
+	for i, username := range usernames {
+		user, err := apiClient.GetUser(context.Background(), username)
+		if err != nil {
+			t.Fatalf("GetUser(%q): %v", username, err)
+		}
+		if received[i] != username || user.Login != username {
+			t.Errorf("GetUser(%q): API получил username=%q", username, received[i])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"auth-service/audit"
+	"auth-service/config"
+	"auth-service/password"
+)
+
+// runCommand выполняет служебную подкоманду, если она указана в аргументах.
+// Возвращает false, если аргументы не содержат подкоманды и нужно запускать сервер.
+func runCommand(args []string) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	switch args[0] {
+	case "verify-audit":
+		return true, verifyAudit(args[1:])
+	case "hash-password":
+		return true, hashPassword(args[1:])
+	case "config":
+		if len(args) > 1 && args[1] == "print" {
+			return true, printConfig(args[2:])
+		}
+		fmt.Fprintln(os.Stderr, "Использование: auth-service config print [флаги]")
+		return true, 2
+	default:
+		return false, 0
+	}
+}
+
+// verifyAudit проверяет цепочку хешей журнала аудита.
+// Путь берется из аргумента или из конфигурации.
+func verifyAudit(args []string) int {
+	cfg, rest, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: %v\n", err)
+		return 2
+	}
+
+	path := cfg.AuditLogPath
+	if len(rest) > 0 {
+		path = rest[0]
+	}
+
+	count, err := audit.Verify(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Журнал аудита %s поврежден: %v\n", path, err)
+		return 1
+	}
+
+	fmt.Printf("Журнал аудита %s цел: %d записей\n", path, count)
+	return 0
+}
+
+// hashPassword читает пароль из первой строки стандартного ввода и выводит его хеш
+// с параметрами и перцем из раздела password_hashing конфигурации
+func hashPassword(args []string) int {
+	cfg, _, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: %v\n", err)
+		return 2
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		fmt.Fprintln(os.Stderr, "Использование: echo -n 'пароль' | auth-service hash-password [флаги]")
+		return 2
+	}
+
+	hash, err := password.New(cfg.PasswordHashing).Hash(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка хеширования пароля: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(hash)
+	return 0
+}
+
+// printConfig выводит итоговую конфигурацию с учетом всех источников, маскируя секреты
+func printConfig(args []string) int {
+	cfg, _, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: %v\n", err)
+		return 2
+	}
+
+	data, err := json.MarshalIndent(cfg.Masked(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка вывода конфигурации: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(string(data))
+	return 0
+}
@@ -2,8 +2,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // Config содержит конфигурацию приложения
@@ -11,33 +20,548 @@ type Config struct {
 	ServiceName string `json:"service_name"`
 	ServerPort  int    `json:"server_port"`
 	LogLevel    string `json:"log_level"`
+	LogFormat   string `json:"log_format"`
+	LogConsole  bool   `json:"log_console"`
+	LogPath     string `json:"log_path"`
 	LocalAPIURL string `json:"local_api_url"`
+
+	// Проверки готовности: путь API базы данных для проверки доступности,
+	// время кеширования результатов и таймаут проверок
+	LocalAPIHealthPath string   `json:"local_api_health_path"`
+	HealthCacheTTL     Duration `json:"health_cache_ttl"`
+	HealthCheckTimeout Duration `json:"health_check_timeout"`
+
+	// Временное изменение уровня логирования через /admin/log-level или SIGUSR1
+	// автоматически отменяется по истечении этого времени
+	LogLevelRevertAfter Duration `json:"log_level_revert_after"`
+
+	// Путь к журналу аудита событий безопасности
+	AuditLogPath string `json:"audit_log_path"`
+
+	// Статические токены доступа к административным эндпоинтам
+	AdminTokens []string `json:"admin_tokens" secret:"true"`
+
+	// Секрет подписи JWT; если не задан, генерируется при каждом запуске
+	JWTSecret string `json:"jwt_secret" secret:"true"`
+
+	// Срок действия выдаваемых токенов
+	TokenTTL Duration `json:"token_ttl"`
+
+	// Таймауты HTTP сервера
+	ServerReadTimeout       Duration `json:"server_read_timeout"`
+	ServerReadHeaderTimeout Duration `json:"server_read_header_timeout"`
+	ServerWriteTimeout      Duration `json:"server_write_timeout"`
+	ServerIdleTimeout       Duration `json:"server_idle_timeout"`
+
+	// Остановка сервиса: сколько ждать после перевода /readyz в неготовность,
+	// прежде чем перестать принимать соединения, и сколько ждать завершения текущих запросов
+	ShutdownDelay   Duration `json:"shutdown_delay"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+
+	// Интервал проверки изменений файла конфигурации; 0 - только по SIGHUP
+	ConfigWatchInterval Duration `json:"config_watch_interval"`
+
+	// Путь к файлу, из которого загружена конфигурация
+	SourcePath string `json:"-"`
+
+	LogRotation RotationConfig  `json:"log_rotation"`
+	LogSinks    []LogSinkConfig `json:"log_sinks"`
+	AccessLog   AccessLogConfig `json:"access_log"`
+	Tracing     TracingConfig   `json:"tracing"`
+	TLS         TLSConfig       `json:"tls"`
+
+	// Отдельный listener для административных эндпоинтов, метрик, pprof и Swagger
+	AdminListener AdminListenerConfig `json:"admin_listener"`
+
+	// Доставка токенов браузерам через cookie с защитой от CSRF
+	SessionCookies SessionCookiesConfig `json:"session_cookies"`
+
+	// Асимметричная подпись токенов и публикация ключей в JWKS
+	Signing SigningConfig `json:"signing"`
+
+	// Хеширование паролей: алгоритм новых хешей, их параметры и перец
+	PasswordHashing PasswordHashingConfig `json:"password_hashing"`
+
+	// Источники токена доступа для защищенных эндпоинтов
+	Credentials CredentialsConfig `json:"credentials"`
+
+	// Ограничение частоты запросов к публичным эндпоинтам
+	RateLimit RateLimitConfig `json:"rate_limit"`
+
+	// Redis (или совместимое хранилище), общее для нескольких реплик
+	Redis RedisConfig `json:"redis"`
+
+	// Отозванные токены, счетчики попыток входа и кеш пользователей
+	SessionState SessionStateConfig `json:"session_state"`
+}
+
+// Типы мест назначения логов
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+)
+
+// RotationConfig содержит настройки ротации файла лога
+type RotationConfig struct {
+	MaxSizeMB      int      `json:"max_size_mb"`     // Размер файла, после которого выполняется ротация
+	MaxBackups     int      `json:"max_backups"`     // Количество хранимых архивных файлов
+	MaxAgeDays     int      `json:"max_age_days"`    // Срок хранения архивных файлов
+	Compress       bool     `json:"compress"`        // Сжимать архивные файлы gzip
+	RotateInterval Duration `json:"rotate_interval"` // Периодическая ротация по времени; 0 - отключена
+}
+
+// LogSinkConfig описывает одно место назначения логов
+type LogSinkConfig struct {
+	Type  string `json:"type"`  // stdout, file или syslog
+	Level string `json:"level"` // Уровень для этого места назначения; пусто - общий log_level
+
+	// Настройки для type=file
+	Path string `json:"path"`
+	RotationConfig
+
+	// Настройки для type=syslog
+	Network string `json:"network"` // Пусто - локальный syslog
+	Address string `json:"address"`
+	Tag     string `json:"tag"`
 }
 
-// LoadConfig загружает и валидирует конфигурацию из JSON файла
+// AccessLogConfig содержит настройки журнала HTTP запросов
+type AccessLogConfig struct {
+	SampleRate   float64  `json:"sample_rate"`   // Доля логируемых успешных запросов (0..1, 0 - только ошибки)
+	ExcludePaths []string `json:"exclude_paths"` // Пути, не попадающие в журнал; суффикс * задает префикс
+	File         string   `json:"file"`          // Отдельный файл журнала; пусто - общий лог
+	RotationConfig
+}
+
+// Экспортеры трассировки
+const (
+	TracingNone   = "none"
+	TracingOTLP   = "otlp"
+	TracingStdout = "stdout"
+)
+
+// TracingConfig содержит настройки трассировки OpenTelemetry
+type TracingConfig struct {
+	Exporter    string  `json:"exporter"`     // none, otlp или stdout
+	Endpoint    string  `json:"endpoint"`     // Адрес коллектора OTLP/HTTP (host:port)
+	Insecure    bool    `json:"insecure"`     // Подключаться к коллектору без TLS
+	SampleRatio float64 `json:"sample_ratio"` // Доля трассируемых запросов без входящего traceparent (0..1)
+}
+
+// Режимы проверки клиентских сертификатов
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// TLSConfig содержит настройки HTTPS
+type TLSConfig struct {
+	Enabled        bool     `json:"enabled"`
+	CertFile       string   `json:"cert_file"`
+	KeyFile        string   `json:"key_file"`
+	MinVersion     string   `json:"min_version"`     // 1.2 или 1.3
+	CipherSuites   []string `json:"cipher_suites"`   // Имена наборов шифров IANA для TLS 1.2; пусто - по умолчанию Go
+	ClientAuth     string   `json:"client_auth"`     // none, request или require
+	ClientCAFile   string   `json:"client_ca_file"`  // CA для проверки клиентских сертификатов (mTLS)
+	ReloadInterval Duration `json:"reload_interval"` // Интервал проверки изменения файлов сертификатов; 0 - без перезагрузки
+}
+
+// applyDefaults устанавливает значения по умолчанию для настроек HTTPS
+func (tls *TLSConfig) applyDefaults() {
+	if tls.MinVersion == "" {
+		tls.MinVersion = "1.2"
+	}
+	if tls.ClientAuth == "" {
+		tls.ClientAuth = ClientAuthNone
+	}
+	if tls.ReloadInterval == 0 {
+		tls.ReloadInterval = Duration(30 * time.Second)
+	}
+}
+
+// Способы аутентификации на административном listener
+const (
+	AdminAuthToken = "token" // Bearer токен из admin_tokens
+	AdminAuthMTLS  = "mtls"  // Клиентский сертификат, проверенный по admin_listener.tls.client_ca_file
+	AdminAuthNone  = "none"  // Без аутентификации; допустимо только для unix сокета
+)
+
+// UnixSocketPrefix обозначает в адресе listener путь к unix сокету
+const UnixSocketPrefix = "unix:"
+
+// AdminListenerConfig содержит настройки административного listener
+type AdminListenerConfig struct {
+	Address string    `json:"address"` // host:port или unix:/путь/к/сокету
+	Auth    string    `json:"auth"`    // token, mtls или none
+	TLS     TLSConfig `json:"tls"`
+}
+
+// Значения атрибута SameSite для cookie сессии
+const (
+	SameSiteLax    = "lax"
+	SameSiteStrict = "strict"
+	SameSiteNone   = "none"
+)
+
+// SessionCookiesConfig содержит настройки cookie сессии
+type SessionCookiesConfig struct {
+	Enabled     bool     `json:"enabled"`
+	SessionName string   `json:"session_name"` // Cookie с токеном доступа
+	RefreshName string   `json:"refresh_name"` // Cookie с токеном обновления, передается только на /token/refresh
+	CSRFName    string   `json:"csrf_name"`    // Cookie с CSRF токеном, доступная JavaScript
+	CSRFHeader  string   `json:"csrf_header"`  // Заголовок, в котором клиент возвращает CSRF токен
+	Domain      string   `json:"domain"`       // Пусто - cookie только для текущего хоста
+	SameSite    string   `json:"same_site"`    // lax, strict или none
+	Insecure    bool     `json:"insecure"`     // Не устанавливать атрибут Secure (только для разработки без HTTPS)
+	RefreshTTL  Duration `json:"refresh_ttl"`  // Срок действия токена обновления
+}
+
+// SigningConfig содержит ключи подписи токенов. Без key_file токены подписываются
+// HS256 секретом jwt_secret и проверить их могут только сервисы, знающие секрет.
+type SigningConfig struct {
+	KeyFile          string   `json:"key_file"`           // PEM закрытого ключа RSA (RS256), ECDSA P-256 (ES256) или Ed25519 (EdDSA)
+	PreviousKeyFiles []string `json:"previous_key_files"` // Прежние ключи (закрытые или открытые): публикуются в JWKS и принимаются при проверке
+	JWKSMaxAge       Duration `json:"jwks_max_age"`       // Время кеширования JWKS клиентами (Cache-Control)
+}
+
+// Алгоритмы хеширования паролей
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
+// PasswordHashingConfig содержит параметры хеширования паролей. Проверяются хеши обоих
+// алгоритмов; algorithm и параметры определяют формат новых хешей.
+type PasswordHashingConfig struct {
+	Algorithm         string `json:"algorithm"`          // argon2id или bcrypt
+	BcryptCost        int    `json:"bcrypt_cost"`        // Стоимость bcrypt (4-31)
+	BcryptMaxCost     int    `json:"bcrypt_max_cost"`    // Наибольшая стоимость хешей bcrypt из БД, которые проверяются
+	Argon2MemoryKiB   int    `json:"argon2_memory_kib"`  // Память argon2id в КиБ
+	Argon2Iterations  int    `json:"argon2_iterations"`  // Число проходов argon2id
+	Argon2Parallelism int    `json:"argon2_parallelism"` // Число потоков argon2id
+	Pepper            string `json:"pepper" secret:"true"`
+	RehashOnLogin     bool   `json:"rehash_on_login"` // Заменять устаревшие хеши в БД после успешного входа
+}
+
+// Источники токена доступа
+const (
+	CredentialSourceHeader = "header"
+	CredentialSourceCookie = "cookie"
+	CredentialSourceQuery  = "query"
+	CredentialSourceBody   = "body"
+)
+
+// CredentialsConfig задает, откуда извлекается токен доступа
+type CredentialsConfig struct {
+	Sources    []string `json:"sources"`     // Порядок проверки: header, cookie, query, body
+	QueryParam string   `json:"query_param"` // Параметр запроса или формы для клиентов без доступа к заголовкам (WebSocket)
+	Realm      string   `json:"realm"`       // realm в заголовке WWW-Authenticate
+}
+
+// Хранилища состояния ограничителя частоты
+const (
+	RateLimitMemory = "memory"
+	RateLimitRedis  = "redis"
+)
+
+// Ключи, по которым ведется учет запросов в правилах ограничения частоты
+const (
+	RateLimitKeyIP       = "ip"
+	RateLimitKeyUsername = "username"
+	RateLimitKeyAPIKey   = "api_key"
+	RateLimitKeyAgency   = "agency"
+	RateLimitKeyGlobal   = "global"
+)
+
+// RateLimitConfig содержит настройки ограничения частоты запросов
+type RateLimitConfig struct {
+	Enabled      bool   `json:"enabled"`
+	Backend      string `json:"backend"`        // memory (в памяти реплики) или redis (общий для реплик)
+	APIKeyHeader string `json:"api_key_header"` // Заголовок с ключом API для правил с key=api_key
+	FailClosed   bool   `json:"fail_closed"`    // Отклонять запросы, если хранилище недоступно
+
+	// Правила задаются только в файле; по умолчанию ограничиваются вход и проверка токена
+	Rules []RateLimitRule `json:"rules"`
+}
+
+// RateLimitRule описывает одно правило: не более limit запросов за period с одного ключа.
+// Запросы учитываются по алгоритму token bucket с емкостью burst.
+type RateLimitRule struct {
+	Name   string   `json:"name"`   // Имя правила в ключах хранилища и метриках
+	Routes []string `json:"routes"` // Маршруты, например /login; * - все маршруты
+	Key    string   `json:"key"`    // ip, username, api_key, agency или global
+	Limit  int      `json:"limit"`
+	Period Duration `json:"period"`
+	Burst  int      `json:"burst"` // Допустимый всплеск; 0 - равен limit
+}
+
+// Хранилища состояния сессий
+const (
+	SessionStateMemory = "memory"
+	SessionStateRedis  = "redis"
+)
+
+// SessionStateConfig содержит настройки состояния сессий: отозванных токенов и семейств
+// токенов обновления, счетчиков неудачных попыток входа и кеша пользователей
+type SessionStateConfig struct {
+	Backend          string   `json:"backend"`            // memory (одна реплика) или redis (общее для реплик)
+	UserCacheTTL     Duration `json:"user_cache_ttl"`     // Срок хранения данных пользователя в кеше; 0 - кеш отключен
+	UserCacheSize    int      `json:"user_cache_size"`    // Максимальное число пользователей в кеше
+	MaxLoginAttempts int      `json:"max_login_attempts"` // Неудачных попыток входа до блокировки; 0 - без блокировки
+	LockoutWindow    Duration `json:"lockout_window"`     // Период учета попыток и длительность блокировки
+}
+
+// RedisConfig содержит параметры подключения к Redis
+type RedisConfig struct {
+	Address     string   `json:"address"` // host:port; пусто - Redis не используется
+	Username    string   `json:"username"`
+	Password    string   `json:"password" secret:"true"`
+	DB          int      `json:"db"`
+	TLS         bool     `json:"tls"`
+	KeyPrefix   string   `json:"key_prefix"` // Префикс всех ключей сервиса
+	DialTimeout Duration `json:"dial_timeout"`
+}
+
+// unsetSampleRate отмечает незаданный access_log.sample_rate: явный 0 отключает
+// журнал успешных запросов и не должен заменяться значением по умолчанию.
+// Бесконечность нельзя записать в JSON, поэтому значение не совпадет с заданным в файле.
+var unsetSampleRate = math.Inf(-1)
+
+// newConfig возвращает конфигурацию, в которой отмечены параметры, для которых
+// нулевое значение допустимо и отличается от значения по умолчанию
+func newConfig() Config {
+	var config Config
+	config.AccessLog.SampleRate = unsetSampleRate
+	return config
+}
+
+// LoadConfig загружает конфигурацию из файла, устанавливает значения по умолчанию и валидирует ее
 func LoadConfig(path string) (*Config, error) {
-	file, err := os.Open(path)
-	if err != nil {
+	config := newConfig()
+	if err := readFile(path, &config); err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	var config Config
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&config); err != nil {
+	config.applyDefaults()
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
+	return &config, nil
+}
+
+// readFile читает конфигурацию из файла поверх уже заданных значений.
+// Формат определяется по расширению: .json, .yaml/.yml или .toml.
+// Неизвестные параметры считаются ошибкой.
+func readFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", "":
+	case ".yaml", ".yml":
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("ошибка разбора YAML: %w", err)
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			return err
+		}
+	case ".toml":
+		var raw map[string]interface{}
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("ошибка разбора TOML: %w", err)
+		}
+		if data, err = json.Marshal(raw); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("неподдерживаемый формат файла конфигурации %q", ext)
+	}
+
+	// YAML и TOML приводятся к JSON, чтобы имена и типы параметров задавались в одном месте
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("ошибка разбора конфигурации: %w", err)
+	}
+	return nil
+}
 
-	// Установка значений по умолчанию, если они не указаны
+// applyDefaults устанавливает значения по умолчанию для незаданных параметров
+func (config *Config) applyDefaults() {
 	if config.ServerPort == 0 {
 		config.ServerPort = 8080
 	}
 	if config.LogLevel == "" {
 		config.LogLevel = "info"
 	}
+	if config.ServerReadTimeout == 0 {
+		config.ServerReadTimeout = Duration(15 * time.Second)
+	}
+	if config.ServerReadHeaderTimeout == 0 {
+		config.ServerReadHeaderTimeout = Duration(5 * time.Second)
+	}
+	if config.ServerWriteTimeout == 0 {
+		config.ServerWriteTimeout = Duration(30 * time.Second)
+	}
+	if config.ServerIdleTimeout == 0 {
+		config.ServerIdleTimeout = Duration(2 * time.Minute)
+	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = Duration(20 * time.Second)
+	}
+	if config.LocalAPIHealthPath == "" {
+		config.LocalAPIHealthPath = "/"
+	}
+	if config.HealthCacheTTL == 0 {
+		config.HealthCacheTTL = Duration(5 * time.Second)
+	}
+	if config.HealthCheckTimeout == 0 {
+		config.HealthCheckTimeout = Duration(2 * time.Second)
+	}
+	if config.TokenTTL == 0 {
+		config.TokenTTL = Duration(7 * 24 * time.Hour)
+	}
+	if config.LogLevelRevertAfter == 0 {
+		config.LogLevelRevertAfter = Duration(15 * time.Minute)
+	}
+	if config.LogFormat == "" {
+		config.LogFormat = "logfmt"
+	}
+	config.TLS.applyDefaults()
+	if config.SessionCookies.SessionName == "" {
+		config.SessionCookies.SessionName = "authka_session"
+	}
+	if config.SessionCookies.RefreshName == "" {
+		config.SessionCookies.RefreshName = "authka_refresh"
+	}
+	if config.SessionCookies.CSRFName == "" {
+		config.SessionCookies.CSRFName = "authka_csrf"
+	}
+	if config.SessionCookies.CSRFHeader == "" {
+		config.SessionCookies.CSRFHeader = "X-CSRF-Token"
+	}
+	if config.SessionCookies.SameSite == "" {
+		config.SessionCookies.SameSite = SameSiteLax
+	}
+	if config.SessionCookies.RefreshTTL == 0 {
+		config.SessionCookies.RefreshTTL = Duration(30 * 24 * time.Hour)
+	}
+	if config.Signing.JWKSMaxAge == 0 {
+		config.Signing.JWKSMaxAge = Duration(5 * time.Minute)
+	}
+	if config.PasswordHashing.Algorithm == "" {
+		config.PasswordHashing.Algorithm = PasswordBcrypt
+	}
+	if config.PasswordHashing.BcryptCost == 0 {
+		config.PasswordHashing.BcryptCost = 10
+	}
+	if config.PasswordHashing.BcryptMaxCost == 0 {
+		config.PasswordHashing.BcryptMaxCost = max(14, config.PasswordHashing.BcryptCost)
+	}
+	if config.PasswordHashing.Argon2MemoryKiB == 0 {
+		config.PasswordHashing.Argon2MemoryKiB = 19 * 1024
+	}
+	if config.PasswordHashing.Argon2Iterations == 0 {
+		config.PasswordHashing.Argon2Iterations = 2
+	}
+	if config.PasswordHashing.Argon2Parallelism == 0 {
+		config.PasswordHashing.Argon2Parallelism = 1
+	}
+	if config.RateLimit.Backend == "" {
+		config.RateLimit.Backend = RateLimitMemory
+	}
+	if config.RateLimit.APIKeyHeader == "" {
+		config.RateLimit.APIKeyHeader = "X-API-Key"
+	}
+	if config.RateLimit.Rules == nil {
+		config.RateLimit.Rules = []RateLimitRule{
+			{Name: "login_ip", Routes: []string{"/login", "/token/create"}, Key: RateLimitKeyIP, Limit: 30, Period: Duration(time.Minute)},
+			{Name: "login_username", Routes: []string{"/login", "/token/create"}, Key: RateLimitKeyUsername, Limit: 10, Period: Duration(time.Minute)},
+			{Name: "verify_ip", Routes: []string{"/token/verify"}, Key: RateLimitKeyIP, Limit: 600, Period: Duration(time.Minute), Burst: 100},
+		}
+	}
+	for i := range config.RateLimit.Rules {
+		if config.RateLimit.Rules[i].Burst == 0 {
+			config.RateLimit.Rules[i].Burst = config.RateLimit.Rules[i].Limit
+		}
+	}
+	if config.Redis.KeyPrefix == "" {
+		config.Redis.KeyPrefix = "auth:"
+	}
+	if config.Redis.DialTimeout == 0 {
+		config.Redis.DialTimeout = Duration(5 * time.Second)
+	}
+	if config.SessionState.Backend == "" {
+		config.SessionState.Backend = SessionStateMemory
+	}
+	if config.SessionState.UserCacheSize == 0 {
+		config.SessionState.UserCacheSize = 10000
+	}
+	if config.SessionState.LockoutWindow == 0 {
+		config.SessionState.LockoutWindow = Duration(15 * time.Minute)
+	}
+	if len(config.Credentials.Sources) == 0 {
+		config.Credentials.Sources = []string{CredentialSourceHeader, CredentialSourceCookie}
+	}
+	if config.Credentials.QueryParam == "" {
+		config.Credentials.QueryParam = "access_token"
+	}
+	if config.Credentials.Realm == "" {
+		config.Credentials.Realm = "auth-service"
+	}
+	if config.AdminListener.Address == "" {
+		config.AdminListener.Address = "127.0.0.1:9090"
+	}
+	if config.AdminListener.Auth == "" {
+		config.AdminListener.Auth = AdminAuthToken
+	}
+	config.AdminListener.TLS.applyDefaults()
+	if config.Tracing.Exporter == "" {
+		config.Tracing.Exporter = TracingNone
+	}
+	if config.Tracing.Endpoint == "" {
+		config.Tracing.Endpoint = "localhost:4318"
+	}
+	if config.Tracing.SampleRatio == 0 {
+		config.Tracing.SampleRatio = 1
+	}
+	if config.AccessLog.SampleRate == unsetSampleRate {
+		config.AccessLog.SampleRate = 1
+	}
+	if config.AccessLog.ExcludePaths == nil {
+		config.AccessLog.ExcludePaths = []string{"/healthz", "/readyz", "/metrics", "/swagger/*"}
+	}
+	if config.AccessLog.MaxSizeMB == 0 {
+		config.AccessLog.MaxSizeMB = 100
+	}
+	if config.AuditLogPath == "" {
+		config.AuditLogPath = "logs/audit.log"
+	}
+	if config.LogPath == "" {
+		config.LogPath = "logs/authka.log"
+	}
+	if config.LogRotation.MaxSizeMB == 0 {
+		config.LogRotation.MaxSizeMB = 100
+	}
+
+	// Без явно заданных мест назначения пишем в файл и, при log_console, в stdout
+	if len(config.LogSinks) == 0 {
+		config.LogSinks = []LogSinkConfig{{Type: SinkFile, Path: config.LogPath, RotationConfig: config.LogRotation}}
+		if config.LogConsole {
+			config.LogSinks = append(config.LogSinks, LogSinkConfig{Type: SinkStdout})
+		}
+	}
+	for i := range config.LogSinks {
+		if config.LogSinks[i].Type == SinkFile && config.LogSinks[i].MaxSizeMB == 0 {
+			config.LogSinks[i].MaxSizeMB = 100
+		}
+	}
 	if config.LocalAPIURL == "" {
 		config.LocalAPIURL = "http://web:8000"
 	}
-
-	return &config, nil
 }
@@ -0,0 +1,43 @@
+// Файл: config/duration.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration представляет длительность, задаваемую в конфигурации строкой вида "24h" или "30s"
+type Duration time.Duration
+
+// UnmarshalJSON разбирает длительность из строки или числа наносекунд
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch value := raw.(type) {
+	case float64:
+		*d = Duration(time.Duration(value))
+	case string:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("некорректная длительность %q: %w", value, err)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("некорректная длительность: %s", string(data))
+	}
+	return nil
+}
+
+// MarshalJSON записывает длительность в виде строки
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Std возвращает значение в виде time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
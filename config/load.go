@@ -0,0 +1,253 @@
+// Файл: config/load.go
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix задает префикс переменных окружения, переопределяющих конфигурацию
+const EnvPrefix = "AUTH_"
+
+// DefaultPath задает путь к файлу конфигурации по умолчанию
+const DefaultPath = "config.json"
+
+// maskedValue заменяет значения секретов при выводе конфигурации
+const maskedValue = "******"
+
+// setting описывает один скалярный параметр конфигурации, доступный для переопределения
+type setting struct {
+	path   string // Путь в JSON, например access_log.sample_rate
+	value  reflect.Value
+	secret bool
+}
+
+// Load собирает конфигурацию из слоев: значения по умолчанию → файл (--config) →
+// переменные окружения AUTH_* → флаги командной строки.
+// Возвращает также аргументы, оставшиеся после разбора флагов.
+func Load(args []string) (*Config, []string, error) {
+	config := newConfig()
+
+	fs := flag.NewFlagSet("auth-service", flag.ContinueOnError)
+	configPath := fs.String("config", "", "путь к файлу конфигурации (по умолчанию $"+EnvPrefix+"CONFIG или "+DefaultPath+")")
+
+	flagPaths := make(map[string]string)
+	for _, s := range settings(&config) {
+		name := flagName(s.path)
+		flagPaths[name] = s.path
+		fs.String(name, "", "переопределяет "+s.path)
+		if s.secret {
+			flagPaths[name+"-file"] = s.path
+			fs.String(name+"-file", "", "читает "+s.path+" из файла")
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	// Файл конфигурации
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path, explicit = os.LookupEnv(EnvPrefix + "CONFIG")
+	}
+	if !explicit {
+		path = DefaultPath
+	}
+	if err := readFile(path, &config); err != nil {
+		// Без явно указанного файла допускаем конфигурацию только из окружения
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("ошибка чтения файла конфигурации %s: %w", path, err)
+		}
+	} else {
+		config.SourcePath = path
+	}
+
+	// Переменные окружения
+	current := settings(&config)
+	byPath := make(map[string]setting, len(current))
+	for _, s := range current {
+		byPath[s.path] = s
+		raw, ok, err := lookupEnv(envName(s.path))
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(s.value, raw); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", envName(s.path), err)
+		}
+	}
+
+	// Флаги командной строки
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		settingPath, ok := flagPaths[f.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+
+		raw := f.Value.String()
+		if strings.HasSuffix(f.Name, "-file") && byPath[settingPath].secret {
+			data, err := os.ReadFile(raw)
+			if err != nil {
+				flagErr = fmt.Errorf("--%s: %w", f.Name, err)
+				return
+			}
+			raw = strings.TrimRight(string(data), "\r\n")
+		}
+
+		if err := setValue(byPath[settingPath].value, raw); err != nil {
+			flagErr = fmt.Errorf("--%s: %w", f.Name, err)
+		}
+	})
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+
+	config.applyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("некорректная конфигурация:\n%w", err)
+	}
+	return &config, fs.Args(), nil
+}
+
+// Masked возвращает копию конфигурации с замаскированными секретами
+func (config *Config) Masked() *Config {
+	data, _ := json.Marshal(config)
+	var masked Config
+	json.Unmarshal(data, &masked)
+
+	for _, s := range settings(&masked) {
+		if !s.secret {
+			continue
+		}
+		switch s.value.Kind() {
+		case reflect.String:
+			if s.value.String() != "" {
+				s.value.SetString(maskedValue)
+			}
+		case reflect.Slice:
+			for i := 0; i < s.value.Len(); i++ {
+				s.value.Index(i).SetString(maskedValue)
+			}
+		}
+	}
+	return &masked
+}
+
+// lookupEnv читает переменную окружения или, при наличии NAME_FILE, содержимое указанного файла
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+	if path, ok := os.LookupEnv(name + "_FILE"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+	return "", false, nil
+}
+
+// envName возвращает имя переменной окружения для параметра
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// flagName возвращает имя флага командной строки для параметра
+func flagName(path string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(path)
+}
+
+// settings перечисляет скалярные параметры конфигурации в порядке объявления полей
+func settings(config *Config) []setting {
+	return collectSettings(reflect.ValueOf(config).Elem(), "")
+}
+
+// collectSettings рекурсивно обходит поля структуры конфигурации
+func collectSettings(v reflect.Value, prefix string) []setting {
+	var result []setting
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		value := v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			result = append(result, collectSettings(value, prefix)...)
+			continue
+		}
+
+		path := prefix + name
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			result = append(result, collectSettings(value, path+".")...)
+		case reflect.Slice:
+			// Списки структур (например, log_sinks) задаются только в файле
+			if field.Type.Elem().Kind() == reflect.String {
+				result = append(result, setting{path: path, value: value, secret: field.Tag.Get("secret") == "true"})
+			}
+		case reflect.String, reflect.Int, reflect.Int64, reflect.Bool, reflect.Float64:
+			result = append(result, setting{path: path, value: value, secret: field.Tag.Get("secret") == "true"})
+		}
+	}
+	return result
+}
+
+// setValue присваивает полю значение, разобранное из строки
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(Duration(0)) {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("некорректная длительность %q", raw)
+		}
+		v.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректное целое число %q", raw)
+		}
+		v.SetInt(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("некорректное логическое значение %q", raw)
+		}
+		v.SetBool(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("некорректное число %q", raw)
+		}
+		v.SetFloat(parsed)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Файл: config/reload.go
+package config
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Change описывает изменение одного параметра конфигурации
+type Change struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// restartOnly перечисляет параметры, которые применяются только при перезапуске сервиса
+var restartOnly = []string{
+	"server_port",
+	"server_read_timeout",
+	"server_read_header_timeout",
+	"server_write_timeout",
+	"server_idle_timeout",
+	"log_format",
+	"log_console",
+	"log_path",
+	"log_rotation",
+	"log_sinks",
+	"access_log.file",
+	"access_log.max_size_mb",
+	"access_log.max_backups",
+	"access_log.max_age_days",
+	"access_log.compress",
+	"access_log.rotate_interval",
+	"audit_log_path",
+	"jwt_secret",
+	"signing.key_file",
+	"signing.previous_key_files",
+	"password_hashing",
+	"rate_limit.backend",
+	"redis",
+	"session_state.backend",
+	"session_state.user_cache_size",
+	"tracing",
+	"tls",
+	"admin_listener",
+}
+
+// IsRestartOnly проверяет, требует ли изменение параметра перезапуска сервиса
+func IsRestartOnly(path string) bool {
+	for _, p := range restartOnly {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepRestartOnly переносит из текущей конфигурации параметры, требующие перезапуска,
+// чтобы новая конфигурация соответствовала уже открытым ресурсам
+func (config *Config) KeepRestartOnly(current *Config) {
+	config.ServerPort = current.ServerPort
+	config.ServerReadTimeout = current.ServerReadTimeout
+	config.ServerReadHeaderTimeout = current.ServerReadHeaderTimeout
+	config.ServerWriteTimeout = current.ServerWriteTimeout
+	config.ServerIdleTimeout = current.ServerIdleTimeout
+	config.LogFormat = current.LogFormat
+	config.LogConsole = current.LogConsole
+	config.LogPath = current.LogPath
+	config.LogRotation = current.LogRotation
+	config.LogSinks = current.LogSinks
+	config.AccessLog.File = current.AccessLog.File
+	config.AccessLog.RotationConfig = current.AccessLog.RotationConfig
+	config.AuditLogPath = current.AuditLogPath
+	config.JWTSecret = current.JWTSecret
+	config.Signing.KeyFile = current.Signing.KeyFile
+	config.Signing.PreviousKeyFiles = current.Signing.PreviousKeyFiles
+	config.PasswordHashing = current.PasswordHashing
+	config.RateLimit.Backend = current.RateLimit.Backend
+	config.Redis = current.Redis
+	config.SessionState.Backend = current.SessionState.Backend
+	config.SessionState.UserCacheSize = current.SessionState.UserCacheSize
+	config.Tracing = current.Tracing
+	config.TLS = current.TLS
+	config.AdminListener = current.AdminListener
+}
+
+// Diff возвращает список изменившихся параметров; значения секретов маскируются
+func Diff(old, new *Config) []Change {
+	oldValues, newValues := flatten(old), flatten(new)
+
+	secrets := make(map[string]bool)
+	for _, s := range settings(&Config{}) {
+		if s.secret {
+			secrets[s.path] = true
+		}
+	}
+
+	paths := make(map[string]bool)
+	for path := range oldValues {
+		paths[path] = true
+	}
+	for path := range newValues {
+		paths[path] = true
+	}
+
+	var changes []Change
+	for path := range paths {
+		oldValue, newValue := oldValues[path], newValues[path]
+		if oldValue == newValue {
+			continue
+		}
+		if secrets[path] {
+			oldValue, newValue = maskedValue, maskedValue+" (изменен)"
+		}
+		changes = append(changes, Change{Path: path, Old: oldValue, New: newValue})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// flatten представляет конфигурацию в виде плоского набора "путь → значение в JSON"
+func flatten(config *Config) map[string]string {
+	data, _ := json.Marshal(config)
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+
+	result := make(map[string]string)
+	flattenInto(result, "", raw)
+	return result
+}
+
+// flattenInto рекурсивно раскрывает вложенные объекты
+func flattenInto(result map[string]string, prefix string, value map[string]interface{}) {
+	for key, v := range value {
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenInto(result, prefix+key+".", nested)
+			continue
+		}
+		data, _ := json.Marshal(v)
+		result[prefix+key] = string(data)
+	}
+}
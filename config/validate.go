@@ -0,0 +1,418 @@
+// Файл: config/validate.go
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// minJWTSecretLength задает минимальную длину секрета подписи HS256 в байтах
+const minJWTSecretLength = 32
+
+// minAdminTokenLength задает минимальную длину административного токена
+const minAdminTokenLength = 16
+
+// maxAdminTokenLength совпадает с ограничением длины токена в заголовке Authorization
+const maxAdminTokenLength = 8192
+
+// adminTokenPattern - синтаксис b64token (RFC 6750, раздел 2.1), которому должен
+// соответствовать токен в заголовке Authorization
+var adminTokenPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~+/]+=*$`)
+
+// maxArgon2MemoryKiB ограничивает память argon2id (1 ГиБ), чтобы проверка пароля не исчерпала память
+const maxArgon2MemoryKiB = 1 << 20
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+var validLogFormats = map[string]bool{"json": true, "logfmt": true}
+
+// Validate проверяет значения конфигурации и возвращает все найденные ошибки сразу
+func (config *Config) Validate() error {
+	var errs []error
+	addf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if config.ServerPort < 1 || config.ServerPort > 65535 {
+		addf("server_port: порт %d вне диапазона 1-65535", config.ServerPort)
+	}
+	if err := validateURL(config.LocalAPIURL); err != nil {
+		addf("local_api_url: %v", err)
+	}
+
+	if !validLogLevels[config.LogLevel] {
+		addf("log_level: неизвестный уровень %q (допустимо: debug, info, warn, error)", config.LogLevel)
+	}
+	if !validLogFormats[config.LogFormat] {
+		addf("log_format: неизвестный формат %q (допустимо: json, logfmt)", config.LogFormat)
+	}
+	for name, value := range map[string]Duration{
+		"server_read_timeout":        config.ServerReadTimeout,
+		"server_read_header_timeout": config.ServerReadHeaderTimeout,
+		"server_write_timeout":       config.ServerWriteTimeout,
+		"server_idle_timeout":        config.ServerIdleTimeout,
+		"shutdown_timeout":           config.ShutdownTimeout,
+		"health_check_timeout":       config.HealthCheckTimeout,
+	} {
+		if value <= 0 {
+			addf("%s: длительность должна быть положительной", name)
+		}
+	}
+	if config.HealthCacheTTL < 0 {
+		addf("health_cache_ttl: длительность не может быть отрицательной")
+	}
+	if !strings.HasPrefix(config.LocalAPIHealthPath, "/") {
+		addf("local_api_health_path: путь должен начинаться с /")
+	}
+	if config.ShutdownDelay < 0 {
+		addf("shutdown_delay: длительность не может быть отрицательной")
+	}
+	if config.TokenTTL <= 0 {
+		addf("token_ttl: длительность должна быть положительной")
+	}
+	if config.ConfigWatchInterval < 0 {
+		addf("config_watch_interval: длительность не может быть отрицательной")
+	}
+	if config.LogLevelRevertAfter < 0 {
+		addf("log_level_revert_after: длительность не может быть отрицательной")
+	}
+	if config.AuditLogPath == "" {
+		addf("audit_log_path: путь не может быть пустым")
+	}
+
+	errs = append(errs, validateRotation("log_rotation", config.LogRotation)...)
+	for i, sink := range config.LogSinks {
+		prefix := fmt.Sprintf("log_sinks[%d]", i)
+		if sink.Level != "" && !validLogLevels[sink.Level] {
+			addf("%s.level: неизвестный уровень %q", prefix, sink.Level)
+		}
+		switch sink.Type {
+		case SinkStdout, SinkSyslog:
+		case SinkFile:
+			if sink.Path == "" {
+				addf("%s.path: для type=file путь обязателен", prefix)
+			}
+			errs = append(errs, validateRotation(prefix, sink.RotationConfig)...)
+		default:
+			addf("%s.type: неизвестный тип %q (допустимо: stdout, file, syslog)", prefix, sink.Type)
+		}
+	}
+
+	if config.AccessLog.SampleRate < 0 || config.AccessLog.SampleRate > 1 {
+		addf("access_log.sample_rate: значение %v вне диапазона [0, 1]", config.AccessLog.SampleRate)
+	}
+	errs = append(errs, validateRotation("access_log", config.AccessLog.RotationConfig)...)
+
+	switch config.Tracing.Exporter {
+	case TracingNone, TracingOTLP, TracingStdout:
+	default:
+		addf("tracing.exporter: неизвестный экспортер %q (допустимо: none, otlp, stdout)", config.Tracing.Exporter)
+	}
+	if config.Tracing.SampleRatio <= 0 || config.Tracing.SampleRatio > 1 {
+		addf("tracing.sample_ratio: значение %v вне диапазона (0, 1]", config.Tracing.SampleRatio)
+	}
+
+	errs = append(errs, validateTLS("tls", config.TLS)...)
+	errs = append(errs, validateAdminListener(config.AdminListener)...)
+
+	switch config.SessionCookies.SameSite {
+	case SameSiteLax, SameSiteStrict:
+	case SameSiteNone:
+		if config.SessionCookies.Insecure {
+			addf("session_cookies.same_site: браузеры принимают SameSite=None только вместе с Secure (insecure=false)")
+		}
+	default:
+		addf("session_cookies.same_site: неизвестное значение %q (допустимо: lax, strict, none)", config.SessionCookies.SameSite)
+	}
+	if config.SessionCookies.RefreshTTL <= 0 {
+		addf("session_cookies.refresh_ttl: длительность должна быть положительной")
+	}
+
+	if len(config.Signing.PreviousKeyFiles) > 0 && config.Signing.KeyFile == "" {
+		addf("signing.previous_key_files: прежние ключи задаются только вместе с signing.key_file")
+	}
+	if config.Signing.JWKSMaxAge < 0 {
+		addf("signing.jwks_max_age: длительность не может быть отрицательной")
+	}
+	errs = append(errs, validatePasswordHashing(config.PasswordHashing)...)
+	errs = append(errs, validateCredentials(config.Credentials)...)
+	errs = append(errs, validateRateLimit(config.RateLimit, config.Redis)...)
+	errs = append(errs, validateSessionState(config.SessionState, config.Redis)...)
+
+	if config.JWTSecret != "" && len(config.JWTSecret) < minJWTSecretLength {
+		addf("jwt_secret: секрет короче %d байт", minJWTSecretLength)
+	}
+	for i, token := range config.AdminTokens {
+		if len(token) < minAdminTokenLength {
+			addf("admin_tokens[%d]: токен короче %d символов", i, minAdminTokenLength)
+		}
+		if len(token) > maxAdminTokenLength || !adminTokenPattern.MatchString(token) {
+			addf("admin_tokens[%d]: токен должен состоять из букв, цифр и символов -._~+/ с завершающими '=' и быть не длиннее %d байт", i, maxAdminTokenLength)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateTLS проверяет настройки HTTPS
+func validateTLS(prefix string, cfg TLSConfig) []error {
+	var errs []error
+	addf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if cfg.Enabled && (cfg.CertFile == "" || cfg.KeyFile == "") {
+		addf("%s: при enabled=true нужны cert_file и key_file", prefix)
+	}
+	if cfg.MinVersion != "1.2" && cfg.MinVersion != "1.3" {
+		addf("%s.min_version: неподдерживаемая версия %q (допустимо: 1.2, 1.3)", prefix, cfg.MinVersion)
+	}
+	for i, name := range cfg.CipherSuites {
+		if !isSecureCipherSuite(name) {
+			addf("%s.cipher_suites[%d]: неизвестный или небезопасный набор шифров %q", prefix, i, name)
+		}
+	}
+	switch cfg.ClientAuth {
+	case ClientAuthNone:
+	case ClientAuthRequest, ClientAuthRequire:
+		if cfg.ClientCAFile == "" {
+			addf("%s.client_ca_file: для client_auth=%s файл CA обязателен", prefix, cfg.ClientAuth)
+		}
+	default:
+		addf("%s.client_auth: неизвестный режим %q (допустимо: none, request, require)", prefix, cfg.ClientAuth)
+	}
+	if cfg.ReloadInterval < 0 {
+		addf("%s.reload_interval: длительность не может быть отрицательной", prefix)
+	}
+	return errs
+}
+
+// validateAdminListener проверяет адрес и способ аутентификации административного listener
+func validateAdminListener(cfg AdminListenerConfig) []error {
+	var errs []error
+	addf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	socketPath, isSocket := strings.CutPrefix(cfg.Address, UnixSocketPrefix)
+	if isSocket {
+		if socketPath == "" {
+			addf("admin_listener.address: не указан путь к unix сокету")
+		}
+	} else if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+		addf("admin_listener.address: некорректный адрес %q: %v", cfg.Address, err)
+	}
+
+	switch cfg.Auth {
+	case AdminAuthToken:
+	case AdminAuthMTLS:
+		if !cfg.TLS.Enabled || cfg.TLS.ClientAuth != ClientAuthRequire {
+			addf("admin_listener.auth: для mtls нужны admin_listener.tls.enabled=true и client_auth=require")
+		}
+	case AdminAuthNone:
+		if !isSocket {
+			addf("admin_listener.auth: none допустимо только для unix сокета")
+		}
+	default:
+		addf("admin_listener.auth: неизвестный способ %q (допустимо: token, mtls, none)", cfg.Auth)
+	}
+
+	errs = append(errs, validateTLS("admin_listener.tls", cfg.TLS)...)
+	return errs
+}
+
+// validatePasswordHashing проверяет параметры хеширования паролей
+func validatePasswordHashing(cfg PasswordHashingConfig) []error {
+	var errs []error
+	addf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch cfg.Algorithm {
+	case PasswordArgon2id, PasswordBcrypt:
+	default:
+		addf("password_hashing.algorithm: неизвестный алгоритм %q (допустимо: argon2id, bcrypt)", cfg.Algorithm)
+	}
+	if cfg.BcryptCost < 4 || cfg.BcryptCost > 31 {
+		addf("password_hashing.bcrypt_cost: значение %d вне диапазона 4-31", cfg.BcryptCost)
+	}
+	if cfg.BcryptMaxCost < cfg.BcryptCost || cfg.BcryptMaxCost > 31 {
+		addf("password_hashing.bcrypt_max_cost: значение %d вне диапазона %d-31", cfg.BcryptMaxCost, cfg.BcryptCost)
+	}
+	if cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 {
+		addf("password_hashing.argon2_parallelism: значение %d вне диапазона 1-255", cfg.Argon2Parallelism)
+	}
+	if cfg.Argon2MemoryKiB < 8*cfg.Argon2Parallelism || cfg.Argon2MemoryKiB > maxArgon2MemoryKiB {
+		addf("password_hashing.argon2_memory_kib: значение %d вне диапазона %d-%d", cfg.Argon2MemoryKiB, 8*cfg.Argon2Parallelism, maxArgon2MemoryKiB)
+	}
+	if cfg.Argon2Iterations < 1 {
+		addf("password_hashing.argon2_iterations: значение должно быть положительным")
+	}
+	return errs
+}
+
+// validateCredentials проверяет источники токена доступа
+func validateCredentials(cfg CredentialsConfig) []error {
+	var errs []error
+	addf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	seen := make(map[string]bool, len(cfg.Sources))
+	for i, source := range cfg.Sources {
+		switch source {
+		case CredentialSourceHeader, CredentialSourceCookie, CredentialSourceQuery, CredentialSourceBody:
+		default:
+			addf("credentials.sources[%d]: неизвестный источник %q (допустимо: header, cookie, query, body)", i, source)
+		}
+		if seen[source] {
+			addf("credentials.sources[%d]: источник %q указан повторно", i, source)
+		}
+		seen[source] = true
+	}
+	if cfg.QueryParam == "" {
+		addf("credentials.query_param: имя параметра не может быть пустым")
+	}
+	// realm передается в кавычках заголовка WWW-Authenticate
+	for _, ch := range cfg.Realm {
+		if ch < 0x20 || ch > 0x7e || ch == '"' || ch == '\\' {
+			addf("credentials.realm: допустимы только печатные ASCII символы без кавычек и обратной косой черты")
+			break
+		}
+	}
+	return errs
+}
+
+// validateRateLimit проверяет правила ограничения частоты и выбранное хранилище
+func validateRateLimit(cfg RateLimitConfig, redis RedisConfig) []error {
+	var errs []error
+	addf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch cfg.Backend {
+	case RateLimitMemory:
+	case RateLimitRedis:
+		if redis.Address == "" {
+			addf("rate_limit.backend: для хранилища redis должен быть задан redis.address")
+		}
+	default:
+		addf("rate_limit.backend: неизвестное хранилище %q (допустимо: memory, redis)", cfg.Backend)
+	}
+	if cfg.APIKeyHeader == "" {
+		addf("rate_limit.api_key_header: имя заголовка не может быть пустым")
+	}
+
+	names := make(map[string]bool, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			addf("rate_limit.rules[%d].name: имя правила не может быть пустым", i)
+		} else if names[rule.Name] {
+			addf("rate_limit.rules[%d].name: правило %q указано повторно", i, rule.Name)
+		}
+		names[rule.Name] = true
+
+		if len(rule.Routes) == 0 {
+			addf("rate_limit.rules[%d].routes: не задан ни один маршрут", i)
+		}
+		for _, route := range rule.Routes {
+			if route != "*" && !strings.HasPrefix(route, "/") {
+				addf("rate_limit.rules[%d].routes: маршрут %q должен начинаться с / или быть *", i, route)
+			}
+		}
+		switch rule.Key {
+		case RateLimitKeyIP, RateLimitKeyUsername, RateLimitKeyAPIKey, RateLimitKeyAgency, RateLimitKeyGlobal:
+		default:
+			addf("rate_limit.rules[%d].key: неизвестный ключ %q (допустимо: ip, username, api_key, agency, global)", i, rule.Key)
+		}
+		if rule.Limit <= 0 {
+			addf("rate_limit.rules[%d].limit: значение должно быть положительным", i)
+		}
+		if rule.Period <= 0 {
+			addf("rate_limit.rules[%d].period: значение должно быть положительным", i)
+		}
+		if rule.Burst < 0 {
+			addf("rate_limit.rules[%d].burst: значение не может быть отрицательным", i)
+		}
+	}
+	return errs
+}
+
+// validateSessionState проверяет хранилище состояния сессий, кеш и блокировку входа
+func validateSessionState(cfg SessionStateConfig, redis RedisConfig) []error {
+	var errs []error
+	addf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch cfg.Backend {
+	case SessionStateMemory:
+	case SessionStateRedis:
+		if redis.Address == "" {
+			addf("session_state.backend: для хранилища redis должен быть задан redis.address")
+		}
+	default:
+		addf("session_state.backend: неизвестное хранилище %q (допустимо: memory, redis)", cfg.Backend)
+	}
+	if cfg.UserCacheTTL < 0 {
+		addf("session_state.user_cache_ttl: значение не может быть отрицательным")
+	}
+	if cfg.UserCacheSize < 1 {
+		addf("session_state.user_cache_size: значение должно быть положительным")
+	}
+	if cfg.MaxLoginAttempts < 0 {
+		addf("session_state.max_login_attempts: значение не может быть отрицательным")
+	}
+	if cfg.LockoutWindow <= 0 {
+		addf("session_state.lockout_window: значение должно быть положительным")
+	}
+	return errs
+}
+
+// isSecureCipherSuite проверяет, что набор шифров известен и не считается небезопасным
+func isSecureCipherSuite(name string) bool {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURL проверяет, что адрес является абсолютным HTTP(S) URL
+func validateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("некорректный URL %q: %v", raw, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("URL %q должен использовать схему http или https", raw)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("в URL %q не указан хост", raw)
+	}
+	return nil
+}
+
+// validateRotation проверяет настройки ротации файла лога
+func validateRotation(prefix string, rotation RotationConfig) []error {
+	var errs []error
+	if rotation.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_size_mb: значение не может быть отрицательным", prefix))
+	}
+	if rotation.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_backups: значение не может быть отрицательным", prefix))
+	}
+	if rotation.MaxAgeDays < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_age_days: значение не может быть отрицательным", prefix))
+	}
+	if rotation.RotateInterval < 0 {
+		errs = append(errs, fmt.Errorf("%s.rotate_interval: длительность не может быть отрицательной", prefix))
+	}
+	return errs
+}
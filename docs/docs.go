@@ -15,9 +15,600 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Возвращает набор JWKS для локальной проверки токенов другими сервисами. При подписи HS256 (без signing.key_file) набор пуст, и токены проверяются через /token/verify.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Открытые ключи подписи",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jwks.Set"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Возвращает записи журнала аудита от новых к старым с фильтрацией по пользователю, агентству, типу события и времени",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Журнал аудита",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя пользователя",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID агентства",
+                        "name": "agency_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Типы событий через запятую",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Максимальное количество записей",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/audit.Event"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Возвращает общий уровень логирования и переопределения по компонентам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Текущие уровни логирования",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LogLevelResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Временно меняет общий уровень логирования или уровень отдельного компонента",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Изменение уровня логирования",
+                "parameters": [
+                    {
+                        "description": "Новый уровень логирования",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LogLevelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Возвращает пользователей агентства или всех агентств, логин которых содержит строку поиска",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID агентства",
+                        "name": "agency_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подстрока логина",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение страницы",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы (не более 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUserList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Возвращает учетную запись, действующие сессии, последний успешный вход и состояние блокировки входа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Сведения о пользователе",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUserDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Завершает сессию пользователя и удаляет его учетную запись из БД",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удаление пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Message"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Запрещает вход и сразу делает недействительными выданные пользователю токены на всех репликах",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Отключение учетной записи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUser"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Снимает отключение учетной записи; пользователь должен войти заново",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Включение учетной записи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUser"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}/password-reset": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Завершает сессию пользователя и запрещает вход до смены пароля; признак снимает API базы данных при смене пароля",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Принудительная смена пароля",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminUser"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Всегда возвращает 200, пока процесс отвечает на запросы; зависимости не проверяются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка жизнеспособности",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/login": {
             "post": {
-                "description": "Выполняет вход в систему и возвращает JWT токен",
+                "description": "Выполняет вход в систему и возвращает JWT токен. При включенных session_cookies также устанавливает cookie сессии, обновления и CSRF токена.",
                 "consumes": [
                     "application/json"
                 ],
@@ -58,11 +649,29 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -74,7 +683,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Выполняет выход пользователя и удаляет токен",
+                "description": "Выполняет выход пользователя, удаляет токен и cookie сессии",
                 "consumes": [
                     "application/json"
                 ],
@@ -85,6 +694,14 @@ const docTemplate = `{
                     "auth"
                 ],
                 "summary": "Выход из системы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CSRF токен из cookie (при аутентификации по cookie)",
+                        "name": "X-CSRF-Token",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -104,11 +721,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет зависимости (API базы данных, ключи подписи) и возвращает результат по каждой с задержкой. Результаты кешируются на health_cache_ttl. Во время остановки сервиса возвращает 503.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Готовность сервиса",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
                     }
                 }
             }
@@ -160,6 +815,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -171,7 +850,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Обновляет JWT токен",
+                "description": "Обновляет JWT токен. Принимает токен доступа в заголовке Authorization или cookie сессии либо cookie обновления; при аутентификации по cookie требует заголовок с CSRF токеном и возвращает новый токен только в cookie.",
                 "consumes": [
                     "application/json"
                 ],
@@ -182,6 +861,14 @@ const docTemplate = `{
                     "auth"
                 ],
                 "summary": "Обновление токена",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CSRF токен из cookie (при аутентификации по cookie)",
+                        "name": "X-CSRF-Token",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -195,8 +882,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -241,8 +946,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -252,12 +969,376 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "audit.Event": {
+            "description": "Запись журнала аудита",
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Кто выполнил действие, если не сам пользователь",
+                    "type": "string",
+                    "example": "admin"
+                },
+                "agency_id": {
+                    "description": "ID агентства",
+                    "type": "integer",
+                    "example": 42
+                },
+                "client_ip": {
+                    "description": "IP клиента",
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
+                "details": {
+                    "description": "Дополнительные сведения",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "hash": {
+                    "description": "Хеш этой записи, включая prev_hash",
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "Результат: success или failure",
+                    "type": "string",
+                    "example": "success"
+                },
+                "prev_hash": {
+                    "description": "Хеш предыдущей записи",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Причина отказа",
+                    "type": "string",
+                    "example": "bad_password"
+                },
+                "request_id": {
+                    "description": "Идентификатор HTTP запроса",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Порядковый номер записи",
+                    "type": "integer",
+                    "example": 1
+                },
+                "time": {
+                    "description": "Время события",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Тип события",
+                    "type": "string",
+                    "example": "login_success"
+                },
+                "username": {
+                    "description": "Пользователь, к которому относится событие",
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "description": "Время выполнения проверок",
+                    "type": "string"
+                },
+                "checks": {
+                    "description": "Результаты по зависимостям",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "status": {
+                    "description": "ok, если все зависимости доступны",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Описание ошибки",
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "description": "Длительность проверки",
+                    "type": "number",
+                    "example": 3.2
+                },
+                "status": {
+                    "description": "ok или fail",
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "jwks.Key": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "description": "Алгоритм подписи",
+                    "type": "string",
+                    "example": "EdDSA"
+                },
+                "crv": {
+                    "description": "Кривая EC или OKP",
+                    "type": "string",
+                    "example": "Ed25519"
+                },
+                "e": {
+                    "description": "Экспонента RSA",
+                    "type": "string"
+                },
+                "kid": {
+                    "description": "Идентификатор ключа (отпечаток RFC 7638)",
+                    "type": "string",
+                    "example": "Fh4m2lGLK5xQ7mCc0uWnTvyQ9tq4pF1O"
+                },
+                "kty": {
+                    "description": "Тип ключа: RSA, EC или OKP",
+                    "type": "string",
+                    "example": "OKP"
+                },
+                "n": {
+                    "description": "Модуль RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "description": "Назначение ключа",
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "description": "Координата X или открытый ключ Ed25519",
+                    "type": "string"
+                },
+                "y": {
+                    "description": "Координата Y",
+                    "type": "string"
+                }
+            }
+        },
+        "jwks.Set": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwks.Key"
+                    }
+                }
+            }
+        },
+        "models.AdminUser": {
+            "description": "Учетная запись пользователя без хеша пароля и токена",
+            "type": "object",
+            "properties": {
+                "agency_id": {
+                    "description": "ID агентства",
+                    "type": "integer",
+                    "example": 42
+                },
+                "disabled": {
+                    "description": "Учетная запись отключена",
+                    "type": "boolean",
+                    "example": false
+                },
+                "has_session": {
+                    "description": "В БД сохранен токен доступа",
+                    "type": "boolean",
+                    "example": true
+                },
+                "password_reset_required": {
+                    "description": "Требуется смена пароля",
+                    "type": "boolean",
+                    "example": false
+                },
+                "username": {
+                    "description": "Логин пользователя",
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "models.AdminUserDetails": {
+            "description": "Учетная запись, сессии и состояние входа пользователя",
+            "type": "object",
+            "properties": {
+                "agency_id": {
+                    "description": "ID агентства",
+                    "type": "integer",
+                    "example": 42
+                },
+                "disabled": {
+                    "description": "Учетная запись отключена",
+                    "type": "boolean",
+                    "example": false
+                },
+                "failed_logins": {
+                    "description": "Неудачные попытки входа в текущем окне блокировки",
+                    "type": "integer",
+                    "example": 0
+                },
+                "has_session": {
+                    "description": "В БД сохранен токен доступа",
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_login": {
+                    "description": "Последний успешный вход по журналу аудита",
+                    "type": "string"
+                },
+                "last_login_ip": {
+                    "description": "IP последнего успешного входа",
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
+                "locked_until": {
+                    "description": "Вход заблокирован до этого времени",
+                    "type": "string"
+                },
+                "password_reset_required": {
+                    "description": "Требуется смена пароля",
+                    "type": "boolean",
+                    "example": false
+                },
+                "sessions": {
+                    "description": "Действующие сессии; сервис хранит один токен доступа на пользователя",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SessionInfo"
+                    }
+                },
+                "username": {
+                    "description": "Логин пользователя",
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "models.AdminUserList": {
+            "description": "Страница списка пользователей",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Размер страницы",
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "description": "Смещение страницы",
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "description": "Всего пользователей по фильтру",
+                    "type": "integer",
+                    "example": 120
+                },
+                "users": {
+                    "description": "Пользователи страницы",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AdminUser"
+                    }
+                }
+            }
+        },
         "models.ErrorResponse": {
+            "description": "Описание ошибки: стабильный код и сообщение на языке из Accept-Language (ru, en)",
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Машиночитаемый код ошибки",
+                    "type": "string",
+                    "example": "invalid_credentials"
+                },
+                "detail": {
+                    "description": "Пояснение к конкретному случаю",
+                    "type": "string"
+                },
                 "error": {
+                    "description": "Совпадает с title; сохранено для совместимости",
+                    "type": "string",
+                    "example": "Неверное имя пользователя или пароль"
+                },
+                "instance": {
+                    "description": "Путь запроса",
+                    "type": "string",
+                    "example": "/login"
+                },
+                "request_id": {
+                    "description": "Идентификатор запроса для поиска в логах",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Код HTTP ответа",
+                    "type": "integer",
+                    "example": 401
+                },
+                "title": {
+                    "description": "Сообщение об ошибке",
+                    "type": "string",
+                    "example": "Неверное имя пользователя или пароль"
+                },
+                "type": {
+                    "description": "URI типа ошибки",
+                    "type": "string",
+                    "example": "urn:auth-service:error:invalid_credentials"
+                }
+            }
+        },
+        "models.LogLevelRequest": {
+            "description": "Запрос на временное изменение уровня логирования",
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "component": {
+                    "description": "Компонент (handlers, middleware, client, access); пусто - общий уровень",
+                    "type": "string",
+                    "example": "handlers"
+                },
+                "duration": {
+                    "description": "Через сколько вернуть прежний уровень; пусто - значение из конфигурации, \"0\" - не возвращать",
+                    "type": "string",
+                    "example": "15m"
+                },
+                "level": {
+                    "description": "Новый уровень логирования",
                     "type": "string",
-                    "example": "Описание ошибки"
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ],
+                    "example": "debug"
+                }
+            }
+        },
+        "models.LogLevelResponse": {
+            "description": "Текущие уровни логирования",
+            "type": "object",
+            "properties": {
+                "components": {
+                    "description": "Переопределения уровней по компонентам",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "level": {
+                    "description": "Общий уровень логирования",
+                    "type": "string",
+                    "example": "info"
                 }
             }
         },
@@ -272,17 +1353,36 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SessionInfo": {
+            "description": "Действующая сессия пользователя",
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "Время истечения",
+                    "type": "string"
+                },
+                "issued_at": {
+                    "description": "Время выпуска",
+                    "type": "string"
+                },
+                "token_id": {
+                    "description": "Идентификатор токена (jti)",
+                    "type": "string",
+                    "example": "4f1c9a0e2b7d4c3a8e6f5d2c1b0a9f8e"
+                }
+            }
+        },
         "models.TokenResponse": {
             "description": "Ответ с токеном доступа",
             "type": "object",
             "properties": {
                 "access_token": {
-                    "description": "JWT токен доступа",
+                    "description": "JWT токен доступа; не передается при обновлении сессии по cookie",
                     "type": "string",
                     "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
                 },
                 "token_type": {
-                    "description": "Тип токена (обычно \"bearer\")",
+                    "description": "Тип токена: \"bearer\" или \"cookie\"",
                     "type": "string",
                     "example": "bearer"
                 }
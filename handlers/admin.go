@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/audit"
+	"auth-service/logger"
+	"auth-service/models"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLogLevel возвращает текущие уровни логирования
+// @Summary Текущие уровни логирования
+// @Description Возвращает общий уровень логирования и переопределения по компонентам
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.LogLevelResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/log-level [get]
+func GetLogLevel(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		level, components := appCtx.Logger.Levels()
+		c.JSON(http.StatusOK, models.LogLevelResponse{Level: level, Components: components})
+	}
+}
+
+// SetLogLevel изменяет уровень логирования без перезапуска сервиса
+// @Summary Изменение уровня логирования
+// @Description Временно меняет общий уровень логирования или уровень отдельного компонента
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.LogLevelRequest true "Новый уровень логирования"
+// @Success 200 {object} models.LogLevelResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/log-level [put]
+func SetLogLevel(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
+		var request models.LogLevelRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Warn("Некорректный запрос на изменение уровня логирования: %v", err)
+			problem.Abort(c, problem.CodeInvalidRequest)
+			return
+		}
+
+		if request.Component != "" && !logger.IsValidComponent(request.Component) {
+			problem.Abort(c, problem.CodeUnknownComponent, request.Component)
+			return
+		}
+
+		revertAfter := appCtx.Config().LogLevelRevertAfter.Std()
+		if request.Duration != "" {
+			duration, err := time.ParseDuration(request.Duration)
+			if err != nil || duration < 0 {
+				problem.Abort(c, problem.CodeInvalidParameter, "duration")
+				return
+			}
+			revertAfter = duration
+		}
+
+		appCtx.Logger.SetLevel(request.Component, logger.ParseLevel(request.Level), revertAfter)
+		appCtx.recordAudit(c, audit.Event{
+			Type:  audit.EventAdminAction,
+			Actor: c.GetString("adminActor"),
+			Details: map[string]string{
+				"action":       "set_log_level",
+				"component":    request.Component,
+				"level":        request.Level,
+				"revert_after": revertAfter.String(),
+			},
+		})
+		log.Warn("Уровень логирования изменен: компонент=%q уровень=%s возврат через %s",
+			request.Component, request.Level, revertAfter)
+
+		level, components := appCtx.Logger.Levels()
+		c.JSON(http.StatusOK, models.LogLevelResponse{Level: level, Components: components})
+	}
+}
+
+// QueryAudit возвращает записи журнала аудита по фильтрам
+// @Summary Журнал аудита
+// @Description Возвращает записи журнала аудита от новых к старым с фильтрацией по пользователю, агентству, типу события и времени
+// @Tags admin
+// @Produce json
+// @Param username query string false "Имя пользователя"
+// @Param agency_id query int false "ID агентства"
+// @Param type query string false "Типы событий через запятую"
+// @Param from query string false "Начало периода (RFC 3339)"
+// @Param to query string false "Конец периода (RFC 3339)"
+// @Param limit query int false "Максимальное количество записей" default(100)
+// @Success 200 {array} audit.Event
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/audit [get]
+func QueryAudit(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
+		filter := audit.Filter{Username: c.Query("username"), Limit: 100}
+
+		if value := c.Query("agency_id"); value != "" {
+			agencyID, err := strconv.Atoi(value)
+			if err != nil {
+				problem.Abort(c, problem.CodeInvalidParameter, "agency_id")
+				return
+			}
+			filter.AgencyID = &agencyID
+		}
+		if value := c.Query("type"); value != "" {
+			filter.Types = strings.Split(value, ",")
+		}
+		for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+			if value := c.Query(param); value != "" {
+				parsed, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					problem.Abort(c, problem.CodeInvalidParameter, param)
+					return
+				}
+				*target = parsed
+			}
+		}
+		if value := c.Query("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				problem.Abort(c, problem.CodeInvalidParameter, "limit")
+				return
+			}
+			filter.Limit = limit
+		}
+
+		events, err := appCtx.Audit.Query(filter)
+		if err != nil {
+			log.Error("Ошибка чтения журнала аудита: %v", err)
+			problem.Abort(c, problem.CodeInternal)
+			return
+		}
+		if events == nil {
+			events = []audit.Event{}
+		}
+
+		c.JSON(http.StatusOK, events)
+	}
+}
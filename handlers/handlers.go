@@ -1,15 +1,28 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"auth-service/audit"
 	"auth-service/client"
 	"auth-service/config"
+	"auth-service/health"
 	"auth-service/logger"
+	"auth-service/metrics"
 	"auth-service/models"
-	"auth-service/utils"
+	"auth-service/password"
+	"auth-service/problem"
+	"auth-service/signing"
+	"auth-service/state"
+	"auth-service/tracing"
+	"auth-service/verifier"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -17,94 +30,306 @@ import (
 
 // AppContext содержит контекст приложения, доступный всем обработчикам
 type AppContext struct {
-	Config    *config.Config
-	SecretKey string
-	Algorithm string
-	TokenTTL  time.Duration
+	config    atomic.Pointer[config.Config]
+	draining  atomic.Bool
+	Keys      *signing.Keys
+	Passwords *password.Hasher
+	Sessions  state.Store
+	Users     *state.UserCache
 	Logger    *logger.ColorfulLogger
+	Audit     *audit.Logger
+	Health    *health.Registry
 }
 
-// Claims представляет данные, хранящиеся в JWT токене
+// Config возвращает текущую конфигурацию приложения
+func (ctx *AppContext) Config() *config.Config {
+	return ctx.config.Load()
+}
+
+// SetConfig атомарно заменяет конфигурацию приложения (например, при перезагрузке)
+func (ctx *AppContext) SetConfig(cfg *config.Config) {
+	ctx.config.Store(cfg)
+}
+
+// Claims представляет данные, хранящиеся в JWT токене. Общие поля описаны
+// в verifier.Claims, которым токены разбирают другие сервисы.
 type Claims struct {
-	Username string `json:"sub"`
-	AgencyID int    `json:"ngy"`
-	jwt.RegisteredClaims
+	verifier.Claims
+
+	// Заполняются только в токенах обновления cookie сессии
+	AccessHash string `json:"ath,omitempty"`
+	Family     string `json:"fam,omitempty"` // Общий для всех токенов обновления одной сессии
+}
+
+// Ошибки проверки токена; по ним выбирается код ответа (см. TokenErrorCode)
+var (
+	ErrInvalidToken = errors.New("некорректный токен")
+	ErrTokenExpired = errors.New("токен истек")
+	ErrTokenRevoked = errors.New("токен не соответствует сохраненному в БД")
+	ErrDisabled     = errors.New("учетная запись отключена")
+	ErrResetNeeded  = errors.New("требуется смена пароля")
+)
+
+// StartDraining переводит сервис в режим остановки: /readyz начинает сообщать о неготовности
+func (ctx *AppContext) StartDraining() {
+	ctx.draining.Store(true)
+}
+
+// IsDraining сообщает, находится ли сервис в режиме остановки
+func (ctx *AppContext) IsDraining() bool {
+	return ctx.draining.Load()
+}
+
+// RequestLogger возвращает логгер с полями текущего HTTP запроса
+func (ctx *AppContext) RequestLogger(c *gin.Context) *logger.ColorfulLogger {
+	return ctx.Logger.Component(logger.ComponentHandlers).
+		WithContext(c.Request.Context()).
+		With(logger.Fields{"route": c.FullPath()})
+}
+
+// recordAudit дописывает событие в журнал аудита, дополняя его данными запроса
+func (ctx *AppContext) recordAudit(c *gin.Context, event audit.Event) {
+	if ctx.Audit == nil {
+		return
+	}
+
+	event.RequestID = logger.RequestIDFromContext(c.Request.Context())
+	event.ClientIP = c.ClientIP()
+	if event.Outcome == "" {
+		event.Outcome = audit.OutcomeSuccess
+	}
+
+	if err := ctx.Audit.Record(event); err != nil {
+		ctx.RequestLogger(c).Error("Ошибка записи события '%s' в журнал аудита: %v", event.Type, err)
+	}
+}
+
+// newTokenID возвращает случайный идентификатор токена (jti), чтобы токены,
+// выпущенные в одну секунду, не совпадали
+func newTokenID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes)
 }
 
 // createToken создает новый JWT токен
-func (ctx *AppContext) createToken(username string, agencyID int) (string, error) {
-	expirationTime := time.Now().Add(ctx.TokenTTL)
+func (ctx *AppContext) createToken(reqCtx context.Context, username string, agencyID int) (string, error) {
+	log := ctx.Logger.Component(logger.ComponentHandlers).
+		WithContext(reqCtx).
+		With(logger.Fields{"username": username, "agency_id": agencyID})
+	expirationTime := time.Now().Add(ctx.Config().TokenTTL.Std())
 
-	claims := &Claims{
+	claims := &Claims{Claims: verifier.Claims{
 		Username: username,
 		AgencyID: agencyID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-	}
+	}}
 
-	token := jwt.NewWithClaims(jwt.GetSigningMethod(ctx.Algorithm), claims)
-	tokenString, err := token.SignedString([]byte(ctx.SecretKey))
+	tokenString, err := ctx.Keys.Sign(claims)
 	if err != nil {
-		ctx.Logger.Error("Ошибка подписи токена: %v", err)
+		log.Error("Ошибка подписи токена: %v", err)
 		return "", err
 	}
 
-	ctx.Logger.Info("Создан новый токен для пользователя '%s' (Agency ID: %d), срок действия до: %s",
+	log.Info("Создан новый токен для пользователя '%s' (Agency ID: %d), срок действия до: %s",
 		username, agencyID, expirationTime.Format(time.RFC3339))
 
 	return tokenString, nil
 }
 
+// CheckKeys проверяет, что ключ подписи доступен и позволяет выпустить и проверить токен
+func (ctx *AppContext) CheckKeys(context.Context) error {
+	if ctx.Keys == nil {
+		return errors.New("ключ подписи не задан")
+	}
+
+	claims := &Claims{Claims: verifier.Claims{Username: "healthcheck", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}}
+	tokenString, err := ctx.Keys.Sign(claims)
+	if err != nil {
+		return fmt.Errorf("ошибка подписи: %w", err)
+	}
+
+	_, err = ctx.Keys.Parse(tokenString, &Claims{})
+	if err != nil {
+		return fmt.Errorf("ошибка проверки подписи: %w", err)
+	}
+	return nil
+}
+
 // ValidateToken проверяет токен и пользователя в базе данных
-func (ctx *AppContext) ValidateToken(tokenString string) (*Claims, error) {
+func (ctx *AppContext) ValidateToken(reqCtx context.Context, tokenString string) (*Claims, error) {
+	reqCtx, span := tracing.Start(reqCtx, "ValidateToken")
+	claims, err := ctx.validateToken(reqCtx, tokenString)
+	tracing.End(span, err)
+	return claims, err
+}
+
+// validateToken выполняет проверки ValidateToken
+func (ctx *AppContext) validateToken(reqCtx context.Context, tokenString string) (*Claims, error) {
+	log := ctx.Logger.Component(logger.ComponentHandlers).WithContext(reqCtx)
+
 	// Сначала разбираем и проверяем токен
-	claims, err := ctx.parseAndValidateToken(tokenString)
+	claims, err := ctx.parseAndValidateToken(log, tokenString)
 	if err != nil {
-		ctx.Logger.Error("Ошибка при проверке токена: %v", err)
-		return nil, errors.New("некорректный токен: " + err.Error())
+		log.Error("Ошибка при проверке токена: %v", err)
+		metrics.ObserveValidationFailure(validationFailureReason(err))
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, fmt.Errorf("%w: %v", ErrTokenExpired, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	log = log.With(logger.Fields{"username": claims.Username, "agency_id": claims.AgencyID})
+
+	// Токен обновления не может использоваться как токен доступа
+	if claims.TokenType != "" {
+		log.Error("Ошибка при проверке токена: передан токен типа '%s'", claims.TokenType)
+		metrics.ObserveValidationFailure(metrics.ValidationMalformed)
+		return nil, fmt.Errorf("%w: неверный тип токена", ErrInvalidToken)
 	}
 
 	// Проверяем наличие имени пользователя в токене
 	if claims.Username == "" {
-		ctx.Logger.Error("Ошибка при проверке токена: отсутствует имя пользователя")
-		return nil, errors.New("некорректный токен: отсутствует имя пользователя")
+		log.Error("Ошибка при проверке токена: отсутствует имя пользователя")
+		metrics.ObserveValidationFailure(metrics.ValidationMissingClaim)
+		return nil, fmt.Errorf("%w: отсутствует имя пользователя", ErrInvalidToken)
 	}
 
-	// Проверяем ID агентства
-	if claims.AgencyID < 0 {
-		ctx.Logger.Error("Ошибка при проверке токена: отсутствует ID агентства")
-		return nil, errors.New("некорректный токен: отсутствует ID агентства")
+	// Проверяем наличие ID агентства так же, как verifier при локальной проверке
+	if !verifier.HasAgencyID(tokenString) {
+		log.Error("Ошибка при проверке токена: отсутствует ID агентства")
+		metrics.ObserveValidationFailure(metrics.ValidationMissingClaim)
+		return nil, fmt.Errorf("%w: отсутствует ID агентства", ErrInvalidToken)
 	}
 
 	// Проверяем срок действия токена
 	if time.Now().After(claims.ExpiresAt.Time) {
-		ctx.Logger.Error("Ошибка при проверке токена: токен истек (%s)", claims.ExpiresAt.Time)
-		return nil, errors.New("токен истек")
+		log.Error("Ошибка при проверке токена: токен истек (%s)", claims.ExpiresAt.Time)
+		metrics.ObserveValidationFailure(metrics.ValidationExpired)
+		return nil, ErrTokenExpired
+	}
+
+	// Отзыв по jti виден всем репликам сразу, независимо от кеша пользователей
+	revoked, err := ctx.Sessions.IsTokenRevoked(reqCtx, claims.ID)
+	if err != nil {
+		log.Error("Ошибка проверки отзыва токена: %v", err)
+		return nil, err
+	}
+	if revoked {
+		log.Error("Ошибка проверки токена: токен отозван")
+		metrics.ObserveValidationFailure(metrics.ValidationRevoked)
+		return nil, ErrTokenRevoked
 	}
 
-	// Получаем информацию о пользователе из БД
-	apiClient := client.NewAPIClient(ctx.Config)
-	user, err := apiClient.GetUser(claims.Username)
+	// Получаем информацию о пользователе из кеша или БД
+	apiClient := client.NewAPIClient(ctx.Config(), ctx.Logger)
+	user, cached, err := ctx.lookupUser(reqCtx, apiClient, claims.Username)
+	if errors.Is(err, client.ErrUnavailable) {
+		log.Error("Ошибка проверки токена: %v", err)
+		return nil, err
+	}
 	if err != nil {
-		ctx.Logger.Error("Ошибка проверки токена: пользователь '%s' не найден", claims.Username)
-		return nil, errors.New("пользователь не найден")
+		log.Error("Ошибка проверки токена: пользователь '%s' не найден", claims.Username)
+		metrics.ObserveValidationFailure(metrics.ValidationUnknownUser)
+		return nil, fmt.Errorf("%w: пользователь не найден", ErrInvalidToken)
+	}
+
+	// Токен мог быть выпущен другой репликой после заполнения кеша: перед отказом
+	// сверяемся с БД
+	if user.JWTToken != tokenString && cached {
+		ctx.Users.Delete(claims.Username)
+		user, _, err = ctx.lookupUser(reqCtx, apiClient, claims.Username)
+		if errors.Is(err, client.ErrUnavailable) {
+			log.Error("Ошибка проверки токена: %v", err)
+			return nil, err
+		}
+		if err != nil {
+			log.Error("Ошибка проверки токена: пользователь '%s' не найден", claims.Username)
+			metrics.ObserveValidationFailure(metrics.ValidationUnknownUser)
+			return nil, fmt.Errorf("%w: пользователь не найден", ErrInvalidToken)
+		}
+	}
+
+	// Отключение и требование смены пароля сбрасывают кеш на всех репликах, поэтому признаки актуальны
+	if user.Disabled {
+		log.Error("Ошибка проверки токена: учетная запись '%s' отключена", claims.Username)
+		metrics.ObserveValidationFailure(metrics.ValidationDisabled)
+		return nil, ErrDisabled
+	}
+	if user.PasswordResetRequired {
+		log.Error("Ошибка проверки токена: для учетной записи '%s' требуется смена пароля", claims.Username)
+		metrics.ObserveValidationFailure(metrics.ValidationResetNeeded)
+		return nil, ErrResetNeeded
 	}
 
 	// Проверяем соответствие токена сохраненному в БД
 	if user.JWTToken != tokenString {
-		ctx.Logger.Error("Ошибка проверки токена: токен не соответствует сохраненному в БД для пользователя '%s'", claims.Username)
-		return nil, errors.New("токен не соответствует сохраненному в БД")
+		log.Error("Ошибка проверки токена: токен не соответствует сохраненному в БД для пользователя '%s'", claims.Username)
+		metrics.ObserveValidationFailure(metrics.ValidationTokenMismatch)
+		return nil, ErrTokenRevoked
 	}
 
-	ctx.Logger.Info("Токен успешно проверен для пользователя '%s'", claims.Username)
+	log.Info("Токен успешно проверен для пользователя '%s'", claims.Username)
 	return claims, nil
 }
 
+// verifyPassword сверяет пароль с хешем в отдельном спане, чтобы время хеширования было видно в трассе.
+// needsRehash сообщает, что хеш следует заменить: устаревшие хеши заменяются при включенном
+// rehash_on_login, а хеши без перца - всегда, чтобы проверка без перца со временем перестала требоваться.
+func (ctx *AppContext) verifyPassword(reqCtx context.Context, username, password, hash string) (ok, needsRehash bool) {
+	_, span := tracing.Start(reqCtx, "password.verify")
+	defer span.End()
+
+	ok, needsRehash, unpeppered := ctx.Passwords.Verify(password, hash)
+	if unpeppered {
+		metrics.ObservePepperFallback()
+		ctx.Logger.Component(logger.ComponentHandlers).WithContext(reqCtx).
+			Warn("Пароль пользователя '%s' подошел только без перца, хеш будет заменен", username)
+		return true, true
+	}
+	return ok, needsRehash && ctx.Config().PasswordHashing.RehashOnLogin
+}
+
+// verifyDummyPassword выполняет сравнение пароля для несуществующего пользователя, чтобы ответ
+// занимал столько же времени, сколько проверка неверного пароля. Спан называется так же,
+// как в verifyPassword, и не выдает отсутствие пользователя в трассе.
+func (ctx *AppContext) verifyDummyPassword(reqCtx context.Context, password string) {
+	_, span := tracing.Start(reqCtx, "password.verify")
+	defer span.End()
+	ctx.Passwords.VerifyDummy(password)
+}
+
+// rehashPassword заменяет устаревший хеш пароля в БД хешем текущего алгоритма с перцем.
+// Ошибки только логируются: вход уже выполнен, а хеш будет заменен при следующем входе.
+func (ctx *AppContext) rehashPassword(c *gin.Context, apiClient *client.APIClient, username, password string) {
+	log := ctx.RequestLogger(c)
+	reqCtx, span := tracing.Start(c.Request.Context(), "password.rehash")
+	defer span.End()
+
+	hash, err := ctx.Passwords.Hash(password)
+	if err != nil {
+		log.Error("Ошибка хеширования пароля пользователя '%s': %v", username, err)
+		return
+	}
+	if err := apiClient.UpdatePassword(reqCtx, username, hash); err != nil {
+		log.Error("Ошибка замены хеша пароля в БД для пользователя '%s': %v", username, err)
+		return
+	}
+	ctx.invalidateUser(reqCtx, username)
+	log.Info("Хеш пароля пользователя '%s' обновлен до текущих параметров", username)
+}
+
 // Login обрабатывает запрос на аутентификацию
 // @Summary Аутентификация пользователя
-// @Description Выполняет вход в систему и возвращает JWT токен
+// @Description Выполняет вход в систему и возвращает JWT токен. При включенных session_cookies также устанавливает cookie сессии, обновления и CSRF токена.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -112,47 +337,96 @@ func (ctx *AppContext) ValidateToken(tokenString string) (*Claims, error) {
 // @Success 200 {object} models.TokenResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Router /login [post]
 func Login(appCtx *AppContext) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
 		var userData models.User
 		if err := c.ShouldBindJSON(&userData); err != nil {
-			appCtx.Logger.Warn("Попытка входа с некорректными данными запроса")
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Некорректные данные запроса"})
+			log.Warn("Попытка входа с некорректными данными запроса")
+			problem.Abort(c, problem.CodeInvalidRequest)
 			return
 		}
 
-		appCtx.Logger.Info("Попытка входа пользователя: %s", userData.Username)
+		log.Info("Попытка входа пользователя: %s", userData.Username)
+
+		if appCtx.abortIfLocked(c, userData.Username) {
+			return
+		}
 
-		apiClient := client.NewAPIClient(appCtx.Config)
-		user, err := apiClient.GetUser(userData.Username)
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		user, err := apiClient.GetUser(c.Request.Context(), userData.Username)
+		if errors.Is(err, client.ErrUnavailable) {
+			log.Error("Ошибка входа пользователя '%s': %v", userData.Username, err)
+			problem.Abort(c, problem.CodeBackendUnavailable)
+			return
+		}
 		if err != nil {
-			appCtx.Logger.Error("Ошибка входа: пользователь '%s' не найден", userData.Username)
-			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Пользователь не найден"})
+			appCtx.verifyDummyPassword(c.Request.Context(), userData.Password)
+			log.Error("Ошибка входа: пользователь '%s' не найден", userData.Username)
+			appCtx.recordAudit(c, audit.Event{
+				Type: audit.EventLoginFailure, Outcome: audit.OutcomeFailure,
+				Username: userData.Username, Reason: "unknown_user",
+			})
+			metrics.ObserveLogin(metrics.LoginUnknownUser)
+			appCtx.recordLoginFailure(c, userData.Username)
+			problem.Abort(c, problem.CodeInvalidCredentials)
 			return
 		}
 
-		if !utils.VerifyPassword(userData.Password, user.Password) {
-			appCtx.Logger.Error("Ошибка входа: неверный пароль для пользователя '%s'", userData.Username)
-			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Неверный пароль"})
+		ok, needsRehash := appCtx.verifyPassword(c.Request.Context(), user.Login, userData.Password, user.Password)
+		if !ok {
+			appCtx.recordLoginFailure(c, userData.Username)
+			log.Error("Ошибка входа: неверный пароль для пользователя '%s'", userData.Username)
+			appCtx.recordAudit(c, audit.Event{
+				Type: audit.EventLoginFailure, Outcome: audit.OutcomeFailure,
+				Username: user.Login, AgencyID: user.AgencyID, Reason: "bad_password",
+			})
+			metrics.ObserveLogin(metrics.LoginBadPassword)
+			problem.Abort(c, problem.CodeInvalidCredentials)
+			return
+		}
+		appCtx.resetLoginFailures(c, userData.Username)
+		if appCtx.abortIfInactive(c, user) {
 			return
 		}
 
-		token, err := appCtx.createToken(user.Login, user.AgencyID)
+		token, err := appCtx.createToken(c.Request.Context(), user.Login, user.AgencyID)
 		if err != nil {
-			appCtx.Logger.Error("Ошибка создания токена для пользователя '%s': %v", userData.Username, err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Ошибка создания токена"})
+			log.Error("Ошибка создания токена для пользователя '%s': %v", userData.Username, err)
+			problem.Abort(c, problem.CodeInternal)
 			return
 		}
 
-		if err := apiClient.UpdateToken(userData.Username, token); err != nil {
-			appCtx.Logger.Error("Ошибка обновления токена в БД для пользователя '%s': %v", userData.Username, err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Ошибка обновления токена в БД"})
+		if err := apiClient.UpdateToken(c.Request.Context(), userData.Username, token); err != nil {
+			log.Error("Ошибка обновления токена в БД для пользователя '%s': %v", userData.Username, err)
+			problem.Abort(c, backendErrorCode(err))
 			return
 		}
+		appCtx.invalidateUser(c.Request.Context(), user.Login)
+		if needsRehash {
+			appCtx.rehashPassword(c, apiClient, user.Login, userData.Password)
+		}
+
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventLoginSuccess, Username: user.Login, AgencyID: user.AgencyID})
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventTokenIssued, Username: user.Login, AgencyID: user.AgencyID})
+		metrics.ObserveLogin(metrics.LoginSuccess)
+		metrics.ObserveToken(metrics.TokenIssued)
+
+		if appCtx.Config().SessionCookies.Enabled {
+			if err := appCtx.setSessionCookies(c, user.Login, user.AgencyID, token, ""); err != nil {
+				log.Error("Ошибка установки cookie сессии для пользователя '%s': %v", userData.Username, err)
+				problem.Abort(c, problem.CodeInternal)
+				return
+			}
+		}
 
-		appCtx.Logger.Info("Успешный вход пользователя: %s", userData.Username)
+		log.Info("Успешный вход пользователя: %s", userData.Username)
 		c.JSON(http.StatusOK, models.TokenResponse{
 			AccessToken: token,
 			TokenType:   "bearer",
@@ -171,50 +445,92 @@ func Login(appCtx *AppContext) gin.HandlerFunc {
 // @Success 200 {object} models.TokenResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Router /token/create [post]
 func CreateToken(appCtx *AppContext) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
 		var form struct {
 			Username string `form:"username" binding:"required"`
 			Password string `form:"password" binding:"required"`
 		}
 
 		if err := c.ShouldBind(&form); err != nil {
-			appCtx.Logger.Warn("Попытка создания токена с некорректными данными формы")
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Некорректные данные формы"})
+			log.Warn("Попытка создания токена с некорректными данными формы")
+			problem.Abort(c, problem.CodeInvalidRequest)
 			return
 		}
 
-		appCtx.Logger.Info("Попытка создания токена для пользователя: %s", form.Username)
+		log.Info("Попытка создания токена для пользователя: %s", form.Username)
 
-		apiClient := client.NewAPIClient(appCtx.Config)
-		user, err := apiClient.GetUser(form.Username)
+		if appCtx.abortIfLocked(c, form.Username) {
+			return
+		}
+
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		user, err := apiClient.GetUser(c.Request.Context(), form.Username)
+		if errors.Is(err, client.ErrUnavailable) {
+			log.Error("Ошибка создания токена для пользователя '%s': %v", form.Username, err)
+			problem.Abort(c, problem.CodeBackendUnavailable)
+			return
+		}
 		if err != nil {
-			appCtx.Logger.Error("Ошибка создания токена: пользователь '%s' не найден", form.Username)
-			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Неверное имя пользователя или пароль"})
+			appCtx.verifyDummyPassword(c.Request.Context(), form.Password)
+			log.Error("Ошибка создания токена: пользователь '%s' не найден", form.Username)
+			appCtx.recordAudit(c, audit.Event{
+				Type: audit.EventLoginFailure, Outcome: audit.OutcomeFailure,
+				Username: form.Username, Reason: "unknown_user",
+			})
+			metrics.ObserveLogin(metrics.LoginUnknownUser)
+			appCtx.recordLoginFailure(c, form.Username)
+			problem.Abort(c, problem.CodeInvalidCredentials)
 			return
 		}
 
-		if !utils.VerifyPassword(form.Password, user.Password) {
-			appCtx.Logger.Error("Ошибка создания токена: неверный пароль для пользователя '%s'", form.Username)
-			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Неверное имя пользователя или пароль"})
+		ok, needsRehash := appCtx.verifyPassword(c.Request.Context(), user.Login, form.Password, user.Password)
+		if !ok {
+			appCtx.recordLoginFailure(c, form.Username)
+			log.Error("Ошибка создания токена: неверный пароль для пользователя '%s'", form.Username)
+			appCtx.recordAudit(c, audit.Event{
+				Type: audit.EventLoginFailure, Outcome: audit.OutcomeFailure,
+				Username: user.Login, AgencyID: user.AgencyID, Reason: "bad_password",
+			})
+			metrics.ObserveLogin(metrics.LoginBadPassword)
+			problem.Abort(c, problem.CodeInvalidCredentials)
+			return
+		}
+		appCtx.resetLoginFailures(c, form.Username)
+		if appCtx.abortIfInactive(c, user) {
 			return
 		}
 
-		token, err := appCtx.createToken(user.Login, user.AgencyID)
+		token, err := appCtx.createToken(c.Request.Context(), user.Login, user.AgencyID)
 		if err != nil {
-			appCtx.Logger.Error("Ошибка создания токена для пользователя '%s': %v", form.Username, err)
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Ошибка создания токена"})
+			log.Error("Ошибка создания токена для пользователя '%s': %v", form.Username, err)
+			problem.Abort(c, problem.CodeInternal)
 			return
 		}
 
-		if err := apiClient.UpdateToken(form.Username, token); err != nil {
-			appCtx.Logger.Error("Ошибка обновления токена в БД для пользователя '%s': %v", form.Username, err)
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Ошибка обновления токена в БД"})
+		if err := apiClient.UpdateToken(c.Request.Context(), form.Username, token); err != nil {
+			log.Error("Ошибка обновления токена в БД для пользователя '%s': %v", form.Username, err)
+			problem.Abort(c, backendErrorCode(err))
 			return
 		}
+		appCtx.invalidateUser(c.Request.Context(), user.Login)
+		if needsRehash {
+			appCtx.rehashPassword(c, apiClient, user.Login, form.Password)
+		}
+
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventLoginSuccess, Username: user.Login, AgencyID: user.AgencyID})
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventTokenIssued, Username: user.Login, AgencyID: user.AgencyID})
+		metrics.ObserveLogin(metrics.LoginSuccess)
+		metrics.ObserveToken(metrics.TokenIssued)
 
-		appCtx.Logger.Info("Успешно создан токен для пользователя: %s", form.Username)
+		log.Info("Успешно создан токен для пользователя: %s", form.Username)
 		c.JSON(http.StatusOK, models.TokenResponse{
 			AccessToken: token,
 			TokenType:   "bearer",
@@ -223,31 +539,66 @@ func CreateToken(appCtx *AppContext) gin.HandlerFunc {
 }
 
 // parseAndValidateToken разбирает и проверяет JWT токен
-func (ctx *AppContext) parseAndValidateToken(tokenString string) (*Claims, error) {
-	ctx.Logger.Debug("Проверка токена: %s...", tokenString[:10]+"...")
+func (ctx *AppContext) parseAndValidateToken(log *logger.ColorfulLogger, tokenString string) (*Claims, error) {
+	log.Debug("Проверка токена длиной %d символов", len(tokenString))
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		if token.Method.Alg() != ctx.Algorithm {
-			return nil, errors.New("некорректный алгоритм подписи")
-		}
-		return []byte(ctx.SecretKey), nil
-	})
+	token, err := ctx.Keys.Parse(tokenString, &Claims{})
 
 	if err != nil {
-		ctx.Logger.Error("Ошибка при разборе токена: %v", err)
+		log.Error("Ошибка при разборе токена: %v", err)
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		ctx.Logger.Info("Токен действителен для пользователя: %s (Agency ID: %d)",
+		log.Info("Токен действителен для пользователя: %s (Agency ID: %d)",
 			claims.Username, claims.AgencyID)
 		return claims, nil
 	}
 
-	ctx.Logger.Info("Токен недействителен")
+	log.Info("Токен недействителен")
 	return nil, errors.New("некорректный токен")
 }
 
+// TokenErrorCode определяет код ответа для ошибки ValidateToken или ValidateRefreshToken
+func TokenErrorCode(err error) problem.Code {
+	switch {
+	case errors.Is(err, client.ErrUnavailable):
+		return problem.CodeBackendUnavailable
+	case errors.Is(err, state.ErrUnavailable):
+		return problem.CodeSessionStoreUnavailable
+	case errors.Is(err, ErrTokenExpired):
+		return problem.CodeTokenExpired
+	case errors.Is(err, ErrTokenRevoked):
+		return problem.CodeTokenRevoked
+	case errors.Is(err, ErrDisabled):
+		return problem.CodeAccountDisabled
+	case errors.Is(err, ErrResetNeeded):
+		return problem.CodePasswordResetRequired
+	default:
+		return problem.CodeInvalidToken
+	}
+}
+
+// backendErrorCode определяет код ответа для ошибки обращения к API базы данных
+func backendErrorCode(err error) problem.Code {
+	if errors.Is(err, client.ErrUnavailable) {
+		return problem.CodeBackendUnavailable
+	}
+	return problem.CodeInternal
+}
+
+// validationFailureReason определяет причину отказа разбора токена для метрик
+func validationFailureReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return metrics.ValidationExpired
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return metrics.ValidationBadSignature
+	default:
+		return metrics.ValidationMalformed
+	}
+}
+
 // VerifyToken обрабатывает запрос на проверку токена
 // @Summary Проверка токена
 // @Description Проверяет валидность JWT токена
@@ -257,15 +608,18 @@ func (ctx *AppContext) parseAndValidateToken(tokenString string) (*Claims, error
 // @Success 200 {object} models.TokenVerifyResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
-// @Failure 404 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Security Bearer
 // @Router /token/verify [post]
 func VerifyToken(appCtx *AppContext) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
 		username := c.GetString("username")
 		agencyID := c.GetInt("agencyID")
 
-		appCtx.Logger.Debug("Запрос на проверку токена для пользователя: %s", username)
+		log.Debug("Запрос на проверку токена для пользователя: %s", username)
 
 		c.JSON(http.StatusOK, models.TokenVerifyResponse{
 			Valid:    true,
@@ -277,40 +631,67 @@ func VerifyToken(appCtx *AppContext) gin.HandlerFunc {
 
 // RefreshToken обрабатывает запрос на обновление токена
 // @Summary Обновление токена
-// @Description Обновляет JWT токен
+// @Description Обновляет JWT токен. Принимает токен доступа в заголовке Authorization или cookie сессии либо cookie обновления; при аутентификации по cookie требует заголовок с CSRF токеном и возвращает новый токен только в cookie.
+// @Param X-CSRF-Token header string false "CSRF токен из cookie (при аутентификации по cookie)"
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.TokenResponse
 // @Failure 400 {object} models.ErrorResponse
-// @Failure 404 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Security Bearer
 // @Router /token/refresh [post]
 // RefreshToken обрабатывает запрос на обновление токена
 func RefreshToken(appCtx *AppContext) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
 		// Получаем данные из контекста, установленные middleware
 		username := c.GetString("username")
 		agencyID := c.GetInt("agencyID")
 
-		appCtx.Logger.Debug("Запрос на обновление токена для пользователя: %s", username)
+		log.Debug("Запрос на обновление токена для пользователя: %s", username)
 
 		// Создаем новый токен и обновляем в БД
-		newToken, err := appCtx.createToken(username, agencyID)
+		newToken, err := appCtx.createToken(c.Request.Context(), username, agencyID)
 		if err != nil {
-			appCtx.Logger.Error("Ошибка создания нового токена для пользователя '%s': %v", username, err)
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Ошибка создания токена"})
+			log.Error("Ошибка создания нового токена для пользователя '%s': %v", username, err)
+			problem.Abort(c, problem.CodeInternal)
 			return
 		}
 
-		apiClient := client.NewAPIClient(appCtx.Config)
-		if err := apiClient.UpdateToken(username, newToken); err != nil {
-			appCtx.Logger.Error("Ошибка обновления токена в БД для пользователя '%s': %v", username, err)
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Ошибка обновления токена в БД"})
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		if err := apiClient.UpdateToken(c.Request.Context(), username, newToken); err != nil {
+			log.Error("Ошибка обновления токена в БД для пользователя '%s': %v", username, err)
+			problem.Abort(c, backendErrorCode(err))
+			return
+		}
+		appCtx.invalidateUser(c.Request.Context(), username)
+
+		// Прежний токен доступа (при обновлении по заголовку) отзывается на всех репликах
+		if err := appCtx.revokeToken(c.Request.Context(), c.GetString("tokenID"), c.GetTime("tokenExpiresAt")); err != nil {
+			log.Error("Ошибка отзыва прежнего токена пользователя '%s': %v", username, err)
+		}
+
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventTokenRefreshed, Username: username, AgencyID: agencyID})
+		metrics.ObserveToken(metrics.TokenRefreshed)
+
+		// Браузеру, аутентифицированному по cookie, токен передается только в cookie,
+		// чтобы он не был доступен JavaScript
+		if IsCookieCredential(c) {
+			if err := appCtx.setSessionCookies(c, username, agencyID, newToken, c.GetString("refreshFamily")); err != nil {
+				log.Error("Ошибка установки cookie сессии для пользователя '%s': %v", username, err)
+				problem.Abort(c, problem.CodeInternal)
+				return
+			}
+			log.Info("Сессия успешно обновлена для пользователя '%s'", username)
+			c.JSON(http.StatusOK, models.TokenResponse{TokenType: "cookie"})
 			return
 		}
 
-		appCtx.Logger.Info("Токен успешно обновлен для пользователя '%s'", username)
+		log.Info("Токен успешно обновлен для пользователя '%s'", username)
 		c.JSON(http.StatusOK, models.TokenResponse{
 			AccessToken: newToken,
 			TokenType:   "bearer",
@@ -320,33 +701,52 @@ func RefreshToken(appCtx *AppContext) gin.HandlerFunc {
 
 // Logout обрабатывает запрос на выход из системы
 // @Summary Выход из системы
-// @Description Выполняет выход пользователя и удаляет токен
+// @Description Выполняет выход пользователя, удаляет токен и cookie сессии
+// @Param X-CSRF-Token header string false "CSRF токен из cookie (при аутентификации по cookie)"
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.Message
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Security Bearer
 // @Router /logout [post]
 // Logout обрабатывает запрос на выход из системы
 func Logout(appCtx *AppContext) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
 		// Получаем данные из контекста, установленные middleware
 		username := c.GetString("username")
 		token := c.GetString("token")
 
-		appCtx.Logger.Debug("Запрос на выход для пользователя: %s", username)
+		log.Debug("Запрос на выход для пользователя: %s", username)
 
-		apiClient := client.NewAPIClient(appCtx.Config)
-		if err := apiClient.DeleteToken(username, token); err != nil {
-			appCtx.Logger.Error("Ошибка удаления токена из БД для пользователя '%s': %v", username, err)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Ошибка удаления токена из БД"})
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		if err := apiClient.DeleteToken(c.Request.Context(), username, token); err != nil {
+			log.Error("Ошибка удаления токена из БД для пользователя '%s': %v", username, err)
+			problem.Abort(c, backendErrorCode(err))
 			return
 		}
+		appCtx.invalidateUser(c.Request.Context(), username)
+
+		// Отзыв по jti действует на всех репликах сразу, даже если в их кеше остался прежний токен
+		if err := appCtx.revokeToken(c.Request.Context(), c.GetString("tokenID"), c.GetTime("tokenExpiresAt")); err != nil {
+			log.Error("Ошибка отзыва токена пользователя '%s': %v", username, err)
+		}
+
+		agencyID := c.GetInt("agencyID")
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventTokenRevoked, Username: username, AgencyID: agencyID})
+		metrics.ObserveToken(metrics.TokenRevoked)
+		appCtx.recordAudit(c, audit.Event{Type: audit.EventLogout, Username: username, AgencyID: agencyID})
+
+		if appCtx.Config().SessionCookies.Enabled {
+			appCtx.clearSessionCookies(c)
+		}
 
-		appCtx.Logger.Info("Успешный выход пользователя: %s", username)
+		log.Info("Успешный выход пользователя: %s", username)
 		c.JSON(http.StatusOK, models.Message{Message: "Успешный выход из системы"})
 	}
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"auth-service/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Liveness сообщает, что процесс жив и обрабатывает запросы
+// @Summary Проверка жизнеспособности
+// @Description Всегда возвращает 200, пока процесс отвечает на запросы; зависимости не проверяются
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+	}
+}
+
+// Readiness сообщает, готов ли сервис принимать запросы
+// @Summary Готовность сервиса
+// @Description Проверяет зависимости (API базы данных, ключи подписи) и возвращает результат по каждой с задержкой. Результаты кешируются на health_cache_ttl. Во время остановки сервиса возвращает 503.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func Readiness(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if appCtx.IsDraining() {
+			c.JSON(http.StatusServiceUnavailable, health.Report{Status: "draining", Checks: map[string]health.Result{}})
+			return
+		}
+
+		cfg := appCtx.Config()
+		report := appCtx.Health.Run(c.Request.Context(), cfg.HealthCacheTTL.Std(), cfg.HealthCheckTimeout.Std())
+		if report.Status != health.StatusOK {
+			appCtx.RequestLogger(c).Warn("Сервис не готов: %v", report.Checks)
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,24 @@
+// Файл: handlers/jwks.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS публикует открытые ключи проверки подписи токенов
+// @Summary Открытые ключи подписи
+// @Description Возвращает набор JWKS для локальной проверки токенов другими сервисами. При подписи HS256 (без signing.key_file) набор пуст, и токены проверяются через /token/verify.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwks.Set
+// @Router /.well-known/jwks.json [get]
+func JWKS(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxAge := appCtx.Config().Signing.JWKSMaxAge.Std()
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+		c.JSON(http.StatusOK, appCtx.Keys.JWKS())
+	}
+}
@@ -0,0 +1,176 @@
+// Файл: handlers/login_timing_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	"auth-service/logger"
+	"auth-service/models"
+	"auth-service/password"
+	"auth-service/problem"
+	"auth-service/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	timingUser     = "user123"
+	timingPassword = "pass123!!"
+
+	// timingSamples - число входов каждого вида
+	timingSamples = 40
+	// timingTolerance - допустимое относительное расхождение квартилей времени ответа
+	timingTolerance = 0.25
+)
+
+// newTimingRouter создает маршрутизатор с обработчиком /login и заглушкой API базы данных,
+// в которой есть только пользователь timingUser
+func newTimingRouter(t *testing.T, hashing config.PasswordHashingConfig) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	hasher := password.New(hashing)
+	hash, err := hasher.Hash(timingPassword)
+	if err != nil {
+		t.Fatalf("ошибка хеширования пароля: %v", err)
+	}
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response struct {
+			Data models.UserData `json:"data"`
+		}
+		if r.URL.Query().Get("username") == timingUser {
+			response.Data = models.UserData{Login: timingUser, Password: hash, AgencyID: 42}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(backend.Close)
+
+	cfg := &config.Config{
+		ServiceName:     "auth-service",
+		LocalAPIURL:     backend.URL,
+		LogLevel:        "error",
+		PasswordHashing: hashing,
+	}
+	log, err := logger.NewColorfulLogger(cfg)
+	if err != nil {
+		t.Fatalf("NewColorfulLogger: %v", err)
+	}
+
+	appCtx := &AppContext{
+		Passwords: hasher,
+		Sessions:  state.NewMemoryStore(),
+		Users:     state.NewUserCache(0),
+		Logger:    log.WithWriter(io.Discard),
+	}
+	appCtx.SetConfig(cfg)
+
+	router := gin.New()
+	router.POST("/login", Login(appCtx))
+	return router
+}
+
+// timedLogin выполняет вход и возвращает ответ и время его обработки
+func timedLogin(router *gin.Engine, username, pass string) (*httptest.ResponseRecorder, time.Duration) {
+	body, _ := json.Marshal(models.User{Username: username, Password: pass})
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	start := time.Now()
+	router.ServeHTTP(recorder, req)
+	return recorder, time.Since(start)
+}
+
+// quantile возвращает квантиль q отсортированной выборки
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+// problemBody разбирает тело ответа об ошибке без полей, различающихся между запросами
+func problemBody(t *testing.T, recorder *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("тело ответа не является JSON: %v\n%s", err, recorder.Body.String())
+	}
+	delete(body, "request_id")
+	return body
+}
+
+func TestLoginTimingDoesNotRevealUsers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("статистический тест времени ответа пропускается в режиме -short")
+	}
+
+	tests := []struct {
+		name    string
+		hashing config.PasswordHashingConfig
+	}{
+		{"bcrypt", config.PasswordHashingConfig{Algorithm: config.PasswordBcrypt, BcryptCost: 8}},
+		{"bcrypt with pepper", config.PasswordHashingConfig{Algorithm: config.PasswordBcrypt, BcryptCost: 8, Pepper: "timing-pepper"}},
+		{"argon2id", config.PasswordHashingConfig{Algorithm: config.PasswordArgon2id, Argon2MemoryKiB: 8 * 1024, Argon2Iterations: 2, Argon2Parallelism: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTimingRouter(t, tt.hashing)
+
+			// Прогрев: первая проверка запоминает параметры хеша, по которым создается фиктивный хеш
+			timedLogin(router, timingUser, "wrong-password")
+			timedLogin(router, "unknown-user", "wrong-password")
+
+			var unknown, badPassword []time.Duration
+			var unknownBody, badPasswordBody map[string]interface{}
+			// Входы чередуются, чтобы изменение нагрузки на машину одинаково сказывалось на обеих выборках
+			for i := 0; i < timingSamples; i++ {
+				recorder, elapsed := timedLogin(router, "unknown-user", "wrong-password")
+				if recorder.Code != http.StatusUnauthorized {
+					t.Fatalf("неизвестный пользователь: статус %d, ожидался 401", recorder.Code)
+				}
+				unknown = append(unknown, elapsed)
+				unknownBody = problemBody(t, recorder)
+
+				recorder, elapsed = timedLogin(router, timingUser, "wrong-password")
+				if recorder.Code != http.StatusUnauthorized {
+					t.Fatalf("неверный пароль: статус %d, ожидался 401", recorder.Code)
+				}
+				badPassword = append(badPassword, elapsed)
+				badPasswordBody = problemBody(t, recorder)
+			}
+
+			if code := unknownBody["code"]; code != string(problem.CodeInvalidCredentials) {
+				t.Errorf("код ошибки %v, ожидался %s", code, problem.CodeInvalidCredentials)
+			}
+			if !reflect.DeepEqual(unknownBody, badPasswordBody) {
+				t.Errorf("ответы различаются:\nнеизвестный пользователь: %v\nневерный пароль: %v", unknownBody, badPasswordBody)
+			}
+
+			sort.Slice(unknown, func(i, j int) bool { return unknown[i] < unknown[j] })
+			sort.Slice(badPassword, func(i, j int) bool { return badPassword[i] < badPassword[j] })
+
+			// Распределения совпадают, если каждый квартиль отличается не больше чем на timingTolerance
+			for _, q := range []float64{0.25, 0.5, 0.75} {
+				unknownQ, badQ := quantile(unknown, q), quantile(badPassword, q)
+				diff := float64(unknownQ - badQ)
+				if diff < 0 {
+					diff = -diff
+				}
+				if diff > timingTolerance*float64(max(unknownQ, badQ)) {
+					t.Errorf("квантиль %.2f времени ответа различается больше чем на %.0f%%: неизвестный пользователь %s, неверный пароль %s",
+						q, timingTolerance*100, unknownQ, badQ)
+				}
+			}
+		})
+	}
+}
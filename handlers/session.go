@@ -0,0 +1,199 @@
+// Файл: handlers/session.go
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"auth-service/client"
+	"auth-service/config"
+	"auth-service/logger"
+	"auth-service/verifier"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Источники учетных данных запроса, сохраняемые middleware в "credentialSource"
+const (
+	CredentialHeader        = "header"
+	CredentialSessionCookie = "session_cookie"
+	CredentialRefreshCookie = "refresh_cookie"
+	CredentialQuery         = "query"
+	CredentialBody          = "body"
+)
+
+// tokenTypeRefresh отмечает в claims токен обновления; токены доступа тип не содержат
+const tokenTypeRefresh = "refresh"
+
+// refreshCookiePath ограничивает передачу cookie обновления одним эндпоинтом
+const refreshCookiePath = "/token/refresh"
+
+// IsCookieCredential сообщает, аутентифицирован ли запрос по cookie (и поэтому требует CSRF проверки)
+func IsCookieCredential(c *gin.Context) bool {
+	source := c.GetString("credentialSource")
+	return source == CredentialSessionCookie || source == CredentialRefreshCookie
+}
+
+// accessTokenHash возвращает хеш токена доступа, которым токен обновления привязан к сессии
+func accessTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// createRefreshToken создает токен обновления, привязанный к текущему токену доступа.
+// После обновления или выхода сохраненный в БД токен доступа меняется, и токен обновления
+// перестает действовать, поэтому каждый токен обновления можно использовать один раз.
+// Токены, выпущенные при обновлении, наследуют семейство family; пустое значение начинает новое.
+func (ctx *AppContext) createRefreshToken(username string, agencyID int, accessToken, family string) (string, error) {
+	if family == "" {
+		family = newTokenID()
+	}
+	now := time.Now()
+	claims := &Claims{
+		Claims: verifier.Claims{
+			Username:  username,
+			AgencyID:  agencyID,
+			TokenType: tokenTypeRefresh,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        newTokenID(),
+				ExpiresAt: jwt.NewNumericDate(now.Add(ctx.Config().SessionCookies.RefreshTTL.Std())),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		},
+		AccessHash: accessTokenHash(accessToken),
+		Family:     family,
+	}
+	return ctx.Keys.Sign(claims)
+}
+
+// ValidateRefreshToken проверяет токен обновления и его привязку к токену доступа, сохраненному в БД.
+// Повторное использование уже обмененного токена считается признаком кражи: семейство токенов
+// обновления сессии отзывается, и обновить ее не сможет ни злоумышленник, ни владелец.
+func (ctx *AppContext) ValidateRefreshToken(reqCtx context.Context, tokenString string) (*Claims, error) {
+	log := ctx.Logger.Component(logger.ComponentHandlers).WithContext(reqCtx)
+
+	claims := &Claims{}
+	_, err := ctx.Keys.Parse(tokenString, claims, jwt.WithExpirationRequired())
+	if err != nil {
+		log.Warn("Ошибка при проверке токена обновления: %v", err)
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, fmt.Errorf("%w: токен обновления истек", ErrTokenExpired)
+		}
+		return nil, fmt.Errorf("%w обновления", ErrInvalidToken)
+	}
+	if claims.TokenType != tokenTypeRefresh || claims.Username == "" {
+		return nil, fmt.Errorf("%w обновления", ErrInvalidToken)
+	}
+
+	if claims.Family != "" {
+		revoked, err := ctx.Sessions.IsFamilyRevoked(reqCtx, claims.Family)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			log.With(logger.Fields{"username": claims.Username}).Warn("Токен обновления из отозванного семейства")
+			return nil, fmt.Errorf("%w: сессия отозвана", ErrTokenRevoked)
+		}
+	}
+
+	// Привязка проверяется по БД, а не по кешу: токен обновления одноразовый
+	apiClient := client.NewAPIClient(ctx.Config(), ctx.Logger)
+	user, err := apiClient.GetUser(reqCtx, claims.Username)
+	if errors.Is(err, client.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: пользователь не найден", ErrInvalidToken)
+	}
+	if user.Disabled {
+		return nil, ErrDisabled
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrResetNeeded
+	}
+
+	expected := accessTokenHash(user.JWTToken)
+	if user.JWTToken == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(claims.AccessHash)) != 1 {
+		log.With(logger.Fields{"username": claims.Username}).
+			Warn("Токен обновления уже использован или сессия завершена")
+		if claims.Family != "" {
+			// Семейство хранится, пока может действовать последний выпущенный в нем токен
+			until := time.Now().Add(ctx.Config().SessionCookies.RefreshTTL.Std())
+			if err := ctx.Sessions.RevokeFamily(reqCtx, claims.Family, until); err != nil {
+				log.Error("Ошибка отзыва семейства токенов обновления: %v", err)
+			}
+		}
+		return nil, fmt.Errorf("%w: токен обновления уже использован", ErrTokenRevoked)
+	}
+
+	return claims, nil
+}
+
+// setSessionCookies выдает браузеру cookie с токеном доступа, токеном обновления и CSRF токеном.
+// family - семейство прежнего токена обновления или пустая строка для новой сессии.
+func (ctx *AppContext) setSessionCookies(c *gin.Context, username string, agencyID int, accessToken, family string) error {
+	cfg := ctx.Config()
+
+	refreshToken, err := ctx.createRefreshToken(username, agencyID, accessToken, family)
+	if err != nil {
+		return err
+	}
+
+	csrfToken := make([]byte, 32)
+	if _, err := rand.Read(csrfToken); err != nil {
+		return err
+	}
+
+	sessionAge := cfg.TokenTTL.Std()
+	refreshAge := cfg.SessionCookies.RefreshTTL.Std()
+	setCookie(c, cfg.SessionCookies, cfg.SessionCookies.SessionName, accessToken, "/", sessionAge, true)
+	setCookie(c, cfg.SessionCookies, cfg.SessionCookies.RefreshName, refreshToken, refreshCookiePath, refreshAge, true)
+	setCookie(c, cfg.SessionCookies, cfg.SessionCookies.CSRFName, base64.RawURLEncoding.EncodeToString(csrfToken), "/", refreshAge, false)
+	return nil
+}
+
+// clearSessionCookies удаляет cookie сессии в браузере
+func (ctx *AppContext) clearSessionCookies(c *gin.Context) {
+	cfg := ctx.Config().SessionCookies
+	setCookie(c, cfg, cfg.SessionName, "", "/", -1, true)
+	setCookie(c, cfg, cfg.RefreshName, "", refreshCookiePath, -1, true)
+	setCookie(c, cfg, cfg.CSRFName, "", "/", -1, false)
+}
+
+// setCookie устанавливает cookie с атрибутами из конфигурации; отрицательный maxAge удаляет ее
+func setCookie(c *gin.Context, cfg config.SessionCookiesConfig, name, value, path string, maxAge time.Duration, httpOnly bool) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Domain,
+		MaxAge:   int(maxAge / time.Second),
+		Secure:   !cfg.Insecure,
+		HttpOnly: httpOnly,
+		SameSite: sameSiteMode(cfg.SameSite),
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// sameSiteMode преобразует значение same_site из конфигурации в режим net/http
+func sameSiteMode(value string) http.SameSite {
+	switch value {
+	case config.SameSiteStrict:
+		return http.SameSiteStrictMode
+	case config.SameSiteNone:
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
@@ -0,0 +1,78 @@
+// Файл: handlers/session_test.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	"auth-service/models"
+	"auth-service/state"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	backends := map[string]func(t *testing.T) state.Store{
+		"memory": func(t *testing.T) state.Store { return state.NewMemoryStore() },
+		"redis": func(t *testing.T) state.Store {
+			client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			store := state.NewRedisStore(client, "auth:")
+			t.Cleanup(func() {
+				store.Close()
+				client.Close()
+			})
+			return store
+		},
+	}
+
+	for name, newStore := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			appCtx, backend := newValidationContext(t)
+			appCtx.Sessions = newStore(t)
+			cfg := *appCtx.Config()
+			cfg.SessionCookies = config.SessionCookiesConfig{RefreshTTL: config.Duration(time.Hour)}
+			appCtx.SetConfig(&cfg)
+
+			// Первая пара токенов сессии
+			access, _ := appCtx.createToken(ctx, "user123", 42)
+			refresh, err := appCtx.createRefreshToken("user123", 42, access, "")
+			if err != nil {
+				t.Fatalf("createRefreshToken: %v", err)
+			}
+			backend.set(models.UserData{Login: "user123", AgencyID: 42, JWTToken: access})
+			claims, err := appCtx.ValidateRefreshToken(ctx, refresh)
+			if err != nil {
+				t.Fatalf("первое обновление: %v", err)
+			}
+
+			// Обновление выдает новую пару в том же семействе
+			rotatedAccess, _ := appCtx.createToken(ctx, "user123", 42)
+			rotated, _ := appCtx.createRefreshToken("user123", 42, rotatedAccess, claims.Family)
+			backend.set(models.UserData{Login: "user123", AgencyID: 42, JWTToken: rotatedAccess})
+
+			// Повторное использование обмененного токена отзывает все семейство
+			if _, err := appCtx.ValidateRefreshToken(ctx, refresh); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("повторное использование: %v, ожидалась ErrTokenRevoked", err)
+			}
+			if revoked, err := appCtx.Sessions.IsFamilyRevoked(ctx, claims.Family); err != nil || !revoked {
+				t.Fatalf("семейство %s не отозвано: (%v, %v)", claims.Family, revoked, err)
+			}
+			if _, err := appCtx.ValidateRefreshToken(ctx, rotated); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("токен отозванного семейства: %v, ожидалась ErrTokenRevoked", err)
+			}
+
+			// Другие сессии пользователя не затронуты
+			otherAccess, _ := appCtx.createToken(ctx, "user123", 42)
+			other, _ := appCtx.createRefreshToken("user123", 42, otherAccess, "")
+			backend.set(models.UserData{Login: "user123", AgencyID: 42, JWTToken: otherAccess})
+			if _, err := appCtx.ValidateRefreshToken(ctx, other); err != nil {
+				t.Fatalf("новая сессия: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Файл: handlers/state.go
+package handlers
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/audit"
+	"auth-service/client"
+	"auth-service/logger"
+	"auth-service/metrics"
+	"auth-service/models"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lookupUser получает пользователя из локального кеша или из API базы данных.
+// cached сообщает, что данные взяты из кеша и могли устареть на user_cache_ttl.
+func (ctx *AppContext) lookupUser(reqCtx context.Context, apiClient *client.APIClient, username string) (user *models.UserData, cached bool, err error) {
+	ttl := ctx.Config().SessionState.UserCacheTTL.Std()
+	// Поколение фиксируется до запроса к БД: если за время запроса пользователя
+	// инвалидируют, полученные данные не попадут в кеш
+	generation := ctx.Users.Generation()
+	if ttl > 0 {
+		if user, ok := ctx.Users.Get(username, ttl); ok {
+			metrics.ObserveUserCache(true)
+			return user, true, nil
+		}
+		metrics.ObserveUserCache(false)
+	}
+
+	user, err = apiClient.GetUser(reqCtx, username)
+	if err != nil {
+		return nil, false, err
+	}
+	if ttl > 0 {
+		ctx.Users.Set(username, user, ttl, generation)
+	}
+	return user, false, nil
+}
+
+// invalidateUser сообщает всем репликам, что токен или пароль пользователя в БД изменился.
+// Ошибка только логируется: устаревшая запись кеша истечет по user_cache_ttl.
+func (ctx *AppContext) invalidateUser(reqCtx context.Context, username string) {
+	ctx.Users.Delete(username)
+	if err := ctx.Sessions.Invalidate(reqCtx, username); err != nil {
+		ctx.Logger.Component(logger.ComponentHandlers).WithContext(reqCtx).
+			Error("Ошибка рассылки сброса кеша пользователя '%s': %v", username, err)
+	}
+}
+
+// revokeToken отзывает токен доступа до истечения его срока действия, чтобы он перестал
+// приниматься на всех репликах независимо от кеша пользователей
+func (ctx *AppContext) revokeToken(reqCtx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return ctx.Sessions.RevokeToken(reqCtx, jti, expiresAt)
+}
+
+// lockoutKey приводит имя пользователя к виду, в котором ведется учет попыток входа,
+// чтобы блокировку нельзя было обойти вариантами написания имени
+func lockoutKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// abortIfLocked отклоняет с кодом 429 попытку входа пользователя, заблокированного после
+// max_login_attempts неудачных попыток. Ошибка хранилища не блокирует вход.
+func (ctx *AppContext) abortIfLocked(c *gin.Context, username string) bool {
+	maxAttempts := ctx.Config().SessionState.MaxLoginAttempts
+	if maxAttempts == 0 {
+		return false
+	}
+
+	log := ctx.RequestLogger(c)
+	failures, retryAfter, err := ctx.Sessions.LoginFailures(c.Request.Context(), lockoutKey(username))
+	if err != nil {
+		log.Error("Ошибка чтения счетчика попыток входа пользователя '%s': %v", username, err)
+		return false
+	}
+	if failures < maxAttempts {
+		return false
+	}
+
+	log.Warn("Вход пользователя '%s' отклонен: блокировка после %d неудачных попыток", username, failures)
+	ctx.recordAudit(c, audit.Event{
+		Type: audit.EventLoginFailure, Outcome: audit.OutcomeFailure,
+		Username: username, Reason: "locked",
+	})
+	metrics.ObserveLogin(metrics.LoginLocked)
+	c.Header("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+	problem.Abort(c, problem.CodeLoginLocked)
+	return true
+}
+
+// recordLoginFailure учитывает неудачную попытку входа, в том числе для несуществующих
+// пользователей, чтобы блокировка не выдавала существование учетной записи
+func (ctx *AppContext) recordLoginFailure(c *gin.Context, username string) {
+	cfg := ctx.Config().SessionState
+	if cfg.MaxLoginAttempts == 0 {
+		return
+	}
+
+	log := ctx.RequestLogger(c)
+	failures, _, err := ctx.Sessions.RecordLoginFailure(c.Request.Context(), lockoutKey(username), cfg.LockoutWindow.Std())
+	if err != nil {
+		log.Error("Ошибка учета неудачной попытки входа пользователя '%s': %v", username, err)
+		return
+	}
+	if failures == cfg.MaxLoginAttempts {
+		log.Warn("Вход пользователя '%s' заблокирован на %s после %d неудачных попыток", username, cfg.LockoutWindow.Std(), failures)
+	}
+}
+
+// resetLoginFailures сбрасывает счетчик неудачных попыток после успешного входа
+func (ctx *AppContext) resetLoginFailures(c *gin.Context, username string) {
+	if ctx.Config().SessionState.MaxLoginAttempts == 0 {
+		return
+	}
+	if err := ctx.Sessions.ResetLoginFailures(c.Request.Context(), lockoutKey(username)); err != nil {
+		ctx.RequestLogger(c).Error("Ошибка сброса счетчика попыток входа пользователя '%s': %v", username, err)
+	}
+}
@@ -0,0 +1,393 @@
+// Файл: handlers/users.go
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"auth-service/audit"
+	"auth-service/client"
+	"auth-service/metrics"
+	"auth-service/models"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Размер страницы списка пользователей
+const (
+	defaultUsersPageSize = 50
+	maxUsersPageSize     = 500
+)
+
+// abortIfInactive отклоняет вход в отключенную учетную запись или в запись, для которой
+// администратор потребовал смену пароля. Вызывается после проверки пароля, чтобы
+// признаки учетной записи не раскрывались без знания пароля.
+func (ctx *AppContext) abortIfInactive(c *gin.Context, user *models.UserData) bool {
+	var reason, outcome string
+	var code problem.Code
+	switch {
+	case user.Disabled:
+		reason, outcome, code = "disabled", metrics.LoginDisabled, problem.CodeAccountDisabled
+	case user.PasswordResetRequired:
+		reason, outcome, code = "password_reset_required", metrics.LoginResetNeeded, problem.CodePasswordResetRequired
+	default:
+		return false
+	}
+
+	ctx.RequestLogger(c).Warn("Вход пользователя '%s' отклонен: %s", user.Login, reason)
+	ctx.recordAudit(c, audit.Event{
+		Type: audit.EventLoginFailure, Outcome: audit.OutcomeFailure,
+		Username: user.Login, AgencyID: user.AgencyID, Reason: reason,
+	})
+	metrics.ObserveLogin(outcome)
+	problem.Abort(c, code)
+	return true
+}
+
+// endSession удаляет токен пользователя из БД и отзывает его на всех репликах
+func (ctx *AppContext) endSession(c *gin.Context, apiClient *client.APIClient, user *models.UserData) error {
+	reqCtx := c.Request.Context()
+	if user.JWTToken != "" {
+		if err := apiClient.DeleteToken(reqCtx, user.Login, user.JWTToken); err != nil {
+			return err
+		}
+		if session, ok := sessionInfo(user.JWTToken); ok {
+			if err := ctx.revokeToken(reqCtx, session.TokenID, session.ExpiresAt); err != nil {
+				ctx.RequestLogger(c).Error("Ошибка отзыва токена пользователя '%s': %v", user.Login, err)
+			}
+		}
+		metrics.ObserveToken(metrics.TokenRevoked)
+	}
+	ctx.invalidateUser(reqCtx, user.Login)
+	return nil
+}
+
+// sessionInfo извлекает сведения о сессии из сохраненного в БД токена. Подпись не
+// проверяется: токен получен из БД, а ключ, которым он подписан, мог быть выведен из ротации.
+func sessionInfo(token string) (models.SessionInfo, bool) {
+	claims := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil || claims.ExpiresAt == nil {
+		return models.SessionInfo{}, false
+	}
+	session := models.SessionInfo{TokenID: claims.ID, ExpiresAt: claims.ExpiresAt.Time}
+	if claims.IssuedAt != nil {
+		session.IssuedAt = claims.IssuedAt.Time
+	}
+	return session, true
+}
+
+// adminUser формирует описание учетной записи без хеша пароля и токена
+func adminUser(user *models.UserData) models.AdminUser {
+	return models.AdminUser{
+		Username:              user.Login,
+		AgencyID:              user.AgencyID,
+		Disabled:              user.Disabled,
+		PasswordResetRequired: user.PasswordResetRequired,
+		HasSession:            user.JWTToken != "",
+	}
+}
+
+// recordUserAction записывает в журнал аудита действие администратора над пользователем
+func (ctx *AppContext) recordUserAction(c *gin.Context, action, username string, agencyID int, details map[string]string) {
+	if details == nil {
+		details = map[string]string{}
+	}
+	details["action"] = action
+	ctx.recordAudit(c, audit.Event{
+		Type:     audit.EventAdminAction,
+		Actor:    c.GetString("adminActor"),
+		Username: username,
+		AgencyID: agencyID,
+		Details:  details,
+	})
+}
+
+// adminGetUser получает пользователя из БД по параметру маршрута и при ошибке отвечает клиенту
+func (ctx *AppContext) adminGetUser(c *gin.Context, apiClient *client.APIClient) (*models.UserData, bool) {
+	username := c.Param("username")
+	user, err := apiClient.GetUser(c.Request.Context(), username)
+	switch {
+	case errors.Is(err, client.ErrUnavailable):
+		ctx.RequestLogger(c).Error("Ошибка получения пользователя '%s': %v", username, err)
+		problem.Abort(c, problem.CodeBackendUnavailable)
+		return nil, false
+	case err != nil:
+		problem.Abort(c, problem.CodeUserNotFound)
+		return nil, false
+	}
+	return user, true
+}
+
+// ListUsers возвращает страницу пользователей с фильтром по агентству и логину
+// @Summary Список пользователей
+// @Description Возвращает пользователей агентства или всех агентств, логин которых содержит строку поиска
+// @Tags admin
+// @Produce json
+// @Param agency_id query int false "ID агентства"
+// @Param search query string false "Подстрока логина"
+// @Param offset query int false "Смещение страницы" default(0)
+// @Param limit query int false "Размер страницы (не более 500)" default(50)
+// @Success 200 {object} models.AdminUserList
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/users [get]
+func ListUsers(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
+		var agencyID *int
+		if value := c.Query("agency_id"); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				problem.Abort(c, problem.CodeInvalidParameter, "agency_id")
+				return
+			}
+			agencyID = &id
+		}
+		offset := 0
+		if value := c.Query("offset"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				problem.Abort(c, problem.CodeInvalidParameter, "offset")
+				return
+			}
+			offset = parsed
+		}
+		limit := defaultUsersPageSize
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > maxUsersPageSize {
+				problem.Abort(c, problem.CodeInvalidParameter, "limit")
+				return
+			}
+			limit = parsed
+		}
+		search := c.Query("search")
+
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		users, total, err := apiClient.ListUsers(c.Request.Context(), agencyID, search, offset, limit)
+		if err != nil {
+			log.Error("Ошибка получения списка пользователей: %v", err)
+			problem.Abort(c, backendErrorCode(err))
+			return
+		}
+
+		response := models.AdminUserList{Users: make([]models.AdminUser, 0, len(users)), Total: total, Offset: offset, Limit: limit}
+		for i := range users {
+			response.Users = append(response.Users, adminUser(&users[i]))
+		}
+
+		details := map[string]string{"search": search, "offset": strconv.Itoa(offset), "limit": strconv.Itoa(limit)}
+		filterAgency := 0
+		if agencyID != nil {
+			filterAgency = *agencyID
+			details["agency_id"] = strconv.Itoa(*agencyID)
+		}
+		appCtx.recordUserAction(c, "list_users", "", filterAgency, details)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// GetUser возвращает учетную запись пользователя, его сессии и сведения о входе
+// @Summary Сведения о пользователе
+// @Description Возвращает учетную запись, действующие сессии, последний успешный вход и состояние блокировки входа
+// @Tags admin
+// @Produce json
+// @Param username path string true "Логин пользователя"
+// @Success 200 {object} models.AdminUserDetails
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{username} [get]
+func GetUser(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		user, ok := appCtx.adminGetUser(c, apiClient)
+		if !ok {
+			return
+		}
+
+		details := models.AdminUserDetails{AdminUser: adminUser(user), Sessions: []models.SessionInfo{}}
+		if session, ok := sessionInfo(user.JWTToken); ok && time.Now().Before(session.ExpiresAt) {
+			details.Sessions = append(details.Sessions, session)
+		}
+
+		if appCtx.Audit != nil {
+			events, err := appCtx.Audit.Query(audit.Filter{Username: user.Login, Types: []string{audit.EventLoginSuccess}, Limit: 1})
+			if err != nil {
+				log.Error("Ошибка чтения журнала аудита: %v", err)
+			} else if len(events) > 0 {
+				details.LastLogin = &events[0].Time
+				details.LastLoginIP = events[0].ClientIP
+			}
+		}
+
+		failures, retryAfter, err := appCtx.Sessions.LoginFailures(c.Request.Context(), lockoutKey(user.Login))
+		if err != nil {
+			log.Error("Ошибка чтения счетчика попыток входа пользователя '%s': %v", user.Login, err)
+		} else {
+			details.FailedLogins = failures
+			if maxAttempts := appCtx.Config().SessionState.MaxLoginAttempts; maxAttempts > 0 && failures >= maxAttempts {
+				lockedUntil := time.Now().Add(retryAfter)
+				details.LockedUntil = &lockedUntil
+			}
+		}
+
+		appCtx.recordUserAction(c, "view_user", user.Login, user.AgencyID, nil)
+		c.JSON(http.StatusOK, details)
+	}
+}
+
+// DisableUser отключает учетную запись и завершает ее сессию
+// @Summary Отключение учетной записи
+// @Description Запрещает вход и сразу делает недействительными выданные пользователю токены на всех репликах
+// @Tags admin
+// @Produce json
+// @Param username path string true "Логин пользователя"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{username}/disable [post]
+func DisableUser(appCtx *AppContext) gin.HandlerFunc {
+	disabled := true
+	return updateUserStatus(appCtx, "disable_user", models.UserStatus{Disabled: &disabled}, true)
+}
+
+// EnableUser снова разрешает вход в отключенную учетную запись
+// @Summary Включение учетной записи
+// @Description Снимает отключение учетной записи; пользователь должен войти заново
+// @Tags admin
+// @Produce json
+// @Param username path string true "Логин пользователя"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{username}/enable [post]
+func EnableUser(appCtx *AppContext) gin.HandlerFunc {
+	disabled := false
+	return updateUserStatus(appCtx, "enable_user", models.UserStatus{Disabled: &disabled}, false)
+}
+
+// ForcePasswordReset требует от пользователя сменить пароль
+// @Summary Принудительная смена пароля
+// @Description Завершает сессию пользователя и запрещает вход до смены пароля; признак снимает API базы данных при смене пароля
+// @Tags admin
+// @Produce json
+// @Param username path string true "Логин пользователя"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{username}/password-reset [post]
+func ForcePasswordReset(appCtx *AppContext) gin.HandlerFunc {
+	required := true
+	return updateUserStatus(appCtx, "force_password_reset", models.UserStatus{PasswordResetRequired: &required}, true)
+}
+
+// updateUserStatus изменяет признаки учетной записи и при endSession завершает ее сессию
+func updateUserStatus(appCtx *AppContext, action string, status models.UserStatus, endSession bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		user, ok := appCtx.adminGetUser(c, apiClient)
+		if !ok {
+			return
+		}
+
+		if err := apiClient.UpdateUser(c.Request.Context(), user.Login, status); err != nil {
+			log.Error("Ошибка изменения учетной записи '%s': %v", user.Login, err)
+			problem.Abort(c, backendErrorCode(err))
+			return
+		}
+		if status.Disabled != nil {
+			user.Disabled = *status.Disabled
+		}
+		if status.PasswordResetRequired != nil {
+			user.PasswordResetRequired = *status.PasswordResetRequired
+		}
+
+		if endSession {
+			if err := appCtx.endSession(c, apiClient, user); err != nil {
+				log.Error("Ошибка завершения сессии пользователя '%s': %v", user.Login, err)
+				problem.Abort(c, backendErrorCode(err))
+				return
+			}
+			user.JWTToken = ""
+		} else {
+			appCtx.invalidateUser(c.Request.Context(), user.Login)
+		}
+
+		appCtx.recordUserAction(c, action, user.Login, user.AgencyID, nil)
+		log.Warn("Администратор %s выполнил %s для пользователя '%s'", c.GetString("adminActor"), action, user.Login)
+
+		c.JSON(http.StatusOK, adminUser(user))
+	}
+}
+
+// DeleteUser удаляет пользователя и завершает его сессию
+// @Summary Удаление пользователя
+// @Description Завершает сессию пользователя и удаляет его учетную запись из БД
+// @Tags admin
+// @Produce json
+// @Param username path string true "Логин пользователя"
+// @Success 200 {object} models.Message
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security Bearer
+// @Router /admin/users/{username} [delete]
+func DeleteUser(appCtx *AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c)
+
+		apiClient := client.NewAPIClient(appCtx.Config(), appCtx.Logger)
+		user, ok := appCtx.adminGetUser(c, apiClient)
+		if !ok {
+			return
+		}
+
+		if err := appCtx.endSession(c, apiClient, user); err != nil {
+			log.Error("Ошибка завершения сессии пользователя '%s': %v", user.Login, err)
+			problem.Abort(c, backendErrorCode(err))
+			return
+		}
+		err := apiClient.DeleteUser(c.Request.Context(), user.Login)
+		if errors.Is(err, client.ErrUserNotFound) {
+			problem.Abort(c, problem.CodeUserNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("Ошибка удаления пользователя '%s': %v", user.Login, err)
+			problem.Abort(c, backendErrorCode(err))
+			return
+		}
+		appCtx.invalidateUser(c.Request.Context(), user.Login)
+
+		appCtx.recordUserAction(c, "delete_user", user.Login, user.AgencyID, nil)
+		log.Warn("Администратор %s удалил пользователя '%s'", c.GetString("adminActor"), user.Login)
+
+		c.JSON(http.StatusOK, models.Message{Message: "Пользователь удален"})
+	}
+}
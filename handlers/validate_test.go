@@ -0,0 +1,190 @@
+// Файл: handlers/validate_test.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"auth-service/client"
+	"auth-service/config"
+	"auth-service/logger"
+	"auth-service/models"
+	"auth-service/problem"
+	"auth-service/signing"
+	"auth-service/state"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// validationBackend - заглушка API базы данных с изменяемыми данными пользователей
+type validationBackend struct {
+	mu    sync.Mutex
+	users map[string]models.UserData
+}
+
+func (b *validationBackend) set(user models.UserData) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.users[user.Login] = user
+}
+
+func (b *validationBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	json.NewEncoder(w).Encode(map[string]models.UserData{"data": b.users[r.URL.Query().Get("username")]})
+}
+
+// newValidationContext создает AppContext с подписью HS256 и заглушкой API базы данных
+func newValidationContext(t *testing.T) (*AppContext, *validationBackend) {
+	t.Helper()
+
+	backend := &validationBackend{users: map[string]models.UserData{}}
+	server := httptest.NewServer(backend)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		ServiceName: "auth-service",
+		LocalAPIURL: server.URL,
+		LogLevel:    "error",
+		TokenTTL:    config.Duration(time.Hour),
+	}
+	log, err := logger.NewColorfulLogger(cfg)
+	if err != nil {
+		t.Fatalf("NewColorfulLogger: %v", err)
+	}
+
+	appCtx := &AppContext{
+		Keys:     signing.NewHMAC("0123456789abcdef0123456789abcdef"),
+		Sessions: state.NewMemoryStore(),
+		Users:    state.NewUserCache(0),
+		Logger:   log.WithWriter(io.Discard),
+	}
+	appCtx.SetConfig(cfg)
+	return appCtx, backend
+}
+
+func TestValidateTokenAccountState(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(user *models.UserData)
+		wantErr  error // nil - токен принят
+		wantCode problem.Code
+	}{
+		{"active", func(*models.UserData) {}, nil, ""},
+		{"disabled", func(u *models.UserData) { u.Disabled = true }, ErrDisabled, problem.CodeAccountDisabled},
+		{"password reset required", func(u *models.UserData) { u.PasswordResetRequired = true }, ErrResetNeeded, problem.CodePasswordResetRequired},
+		{"token replaced", func(u *models.UserData) { u.JWTToken = "other" }, ErrTokenRevoked, problem.CodeTokenRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appCtx, backend := newValidationContext(t)
+			token, err := appCtx.createToken(context.Background(), "user123", 42)
+			if err != nil {
+				t.Fatalf("createToken: %v", err)
+			}
+			user := models.UserData{Login: "user123", AgencyID: 42, JWTToken: token}
+			tt.modify(&user)
+			backend.set(user)
+
+			claims, err := appCtx.ValidateToken(context.Background(), token)
+			if tt.wantErr == nil {
+				if err != nil || claims.Username != "user123" || claims.AgencyID != 42 {
+					t.Fatalf("получено (%+v, %v), ожидался принятый токен", claims, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if code := TokenErrorCode(err); code != tt.wantCode {
+				t.Fatalf("код %s, ожидался %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRequiresAgencyClaim(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr bool
+	}{
+		{"agency 0", jwt.MapClaims{"sub": "user123", "ngy": 0}, false},
+		{"agency 42", jwt.MapClaims{"sub": "user123", "ngy": 42}, false},
+		{"no agency", jwt.MapClaims{"sub": "user123"}, true},
+		{"null agency", jwt.MapClaims{"sub": "user123", "ngy": nil}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appCtx, backend := newValidationContext(t)
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			token, err := appCtx.Keys.Sign(tt.claims)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			backend.set(models.UserData{Login: "user123", JWTToken: token})
+
+			_, err = appCtx.ValidateToken(context.Background(), token)
+			if tt.wantErr && !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ошибка %v, ожидалась ErrInvalidToken", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		})
+	}
+}
+
+func TestLookupUserDropsFetchRacingInvalidation(t *testing.T) {
+	appCtx, backend := newValidationContext(t)
+	appCtx.Users = state.NewUserCache(10)
+	cfg := *appCtx.Config()
+	cfg.SessionState.UserCacheTTL = config.Duration(time.Minute)
+	appCtx.SetConfig(&cfg)
+	backend.set(models.UserData{Login: "user123", JWTToken: "old"})
+
+	// Ответ API задерживается, пока токен пользователя меняется и кеш инвалидируется
+	started, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backend.mu.Lock()
+		user := backend.users["user123"]
+		backend.mu.Unlock()
+		close(started)
+		<-release
+		json.NewEncoder(w).Encode(map[string]models.UserData{"data": user})
+	}))
+	defer server.Close()
+	slowCfg := cfg
+	slowCfg.LocalAPIURL = server.URL
+	apiClient := client.NewAPIClient(&slowCfg, appCtx.Logger)
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := appCtx.lookupUser(context.Background(), apiClient, "user123")
+		done <- err
+	}()
+	<-started
+	backend.set(models.UserData{Login: "user123", JWTToken: "new"})
+	appCtx.invalidateUser(context.Background(), "user123")
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("lookupUser: %v", err)
+	}
+
+	if user, ok := appCtx.Users.Get("user123", time.Minute); ok {
+		t.Fatalf("в кеше данные, полученные до инвалидации: токен %q", user.JWTToken)
+	}
+	user, cached, err := appCtx.lookupUser(context.Background(), client.NewAPIClient(appCtx.Config(), appCtx.Logger), "user123")
+	if err != nil || cached || user.JWTToken != "new" {
+		t.Fatalf("lookupUser: (%+v, %v, %v), ожидались новые данные из БД", user, cached, err)
+	}
+}
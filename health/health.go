@@ -0,0 +1,109 @@
+// Файл: health/health.go
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Статусы проверок
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// CheckFunc проверяет доступность одной зависимости
+type CheckFunc func(ctx context.Context) error
+
+// Result содержит результат проверки одной зависимости
+type Result struct {
+	Status    string  `json:"status" example:"ok"`      // ok или fail
+	LatencyMS float64 `json:"latency_ms" example:"3.2"` // Длительность проверки
+	Error     string  `json:"error,omitempty"`          // Описание ошибки
+}
+
+// Report содержит сводный результат проверки зависимостей
+type Report struct {
+	Status    string            `json:"status" example:"ok"` // ok, если все зависимости доступны
+	CheckedAt time.Time         `json:"checked_at"`          // Время выполнения проверок
+	Checks    map[string]Result `json:"checks"`              // Результаты по зависимостям
+}
+
+// namedCheck связывает проверку с именем зависимости
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Registry хранит проверки зависимостей и кеширует их результаты,
+// чтобы частые запросы проб не нагружали зависимости
+type Registry struct {
+	mu       sync.Mutex
+	checks   []namedCheck
+	cached   *Report
+	cachedAt time.Time
+}
+
+// NewRegistry создает пустой набор проверок
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register добавляет проверку зависимости
+func (r *Registry) Register(name string, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+	r.cached = nil
+}
+
+// Run выполняет все проверки параллельно с ограничением по времени.
+// Результат, полученный не ранее cacheTTL назад, возвращается из кеша.
+func (r *Registry) Run(ctx context.Context, cacheTTL, timeout time.Duration) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cached != nil && time.Since(r.cachedAt) < cacheTTL {
+		return *r.cached
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]Result, len(r.checks))
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = runCheck(checkCtx, c.check)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, CheckedAt: time.Now().UTC(), Checks: make(map[string]Result, len(r.checks))}
+	for i, c := range r.checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	r.cached = &report
+	r.cachedAt = time.Now()
+	return report
+}
+
+// runCheck выполняет одну проверку и замеряет ее длительность
+func runCheck(ctx context.Context, check CheckFunc) Result {
+	start := time.Now()
+	err := check(ctx)
+	result := Result{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+// Файл: jwks/jwks.go
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Алгоритмы подписи, публикуемые в JWKS
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key представляет открытый ключ в формате JWK (RFC 7517)
+type Key struct {
+	Kty string `json:"kty" example:"OKP"`                                        // Тип ключа: RSA, EC или OKP
+	Use string `json:"use,omitempty" example:"sig"`                              // Назначение ключа
+	Kid string `json:"kid,omitempty" example:"Fh4m2lGLK5xQ7mCc0uWnTvyQ9tq4pF1O"` // Идентификатор ключа (отпечаток RFC 7638)
+	Alg string `json:"alg,omitempty" example:"EdDSA"`                            // Алгоритм подписи
+	N   string `json:"n,omitempty"`                                              // Модуль RSA
+	E   string `json:"e,omitempty"`                                              // Экспонента RSA
+	Crv string `json:"crv,omitempty" example:"Ed25519"`                          // Кривая EC или OKP
+	X   string `json:"x,omitempty"`                                              // Координата X или открытый ключ Ed25519
+	Y   string `json:"y,omitempty"`                                              // Координата Y
+}
+
+// Set представляет набор ключей JWKS
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// Find возвращает ключ с указанным идентификатором
+func (s Set) Find(kid string) (Key, bool) {
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Algorithm определяет алгоритм подписи для открытого ключа.
+// Поддерживаются RSA (RS256), ECDSA P-256 (ES256) и Ed25519 (EdDSA).
+func Algorithm(pub crypto.PublicKey) (string, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return "", fmt.Errorf("неподдерживаемая кривая ECDSA %s (допустимо: P-256)", pub.Curve.Params().Name)
+		}
+		return AlgorithmES256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("неподдерживаемый тип ключа %T", pub)
+	}
+}
+
+// NewKey создает JWK для открытого ключа; идентификатором ключа служит его отпечаток
+func NewKey(pub crypto.PublicKey) (Key, error) {
+	alg, err := Algorithm(pub)
+	if err != nil {
+		return Key{}, err
+	}
+
+	key := Key{Use: "sig", Alg: alg}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = encode(pub.N.Bytes())
+		key.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		key.Kty = "EC"
+		key.Crv = "P-256"
+		key.X = encode(pub.X.FillBytes(make([]byte, 32)))
+		key.Y = encode(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		key.Kty = "OKP"
+		key.Crv = "Ed25519"
+		key.X = encode(pub)
+	}
+
+	if key.Kid, err = key.Thumbprint(); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+// PublicKey восстанавливает открытый ключ из JWK
+func (k Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный модуль RSA: %w", err)
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("некорректная экспонента RSA: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("некорректная экспонента RSA")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("ключ RSA короче 2048 бит")
+		}
+		return pub, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("неподдерживаемая кривая %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("некорректная координата X: %w", err)
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("некорректная координата Y: %w", err)
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("точка не принадлежит кривой P-256")
+		}
+		return pub, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("неподдерживаемая кривая %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("некорректный ключ Ed25519")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа %q", k.Kty)
+	}
+}
+
+// Thumbprint вычисляет отпечаток ключа по RFC 7638
+func (k Key) Thumbprint() (string, error) {
+	// Обязательные параметры в лексикографическом порядке
+	var members any
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	default:
+		return "", fmt.Errorf("неподдерживаемый тип ключа %q", k.Kty)
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return encode(sum[:]), nil
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("значение отсутствует")
+	}
+	return base64.RawURLEncoding.DecodeString(value)
+}
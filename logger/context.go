@@ -0,0 +1,46 @@
+// Файл: logger/context.go
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// ContextWithRequestID сохраняет идентификатор запроса в контексте
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext извлекает идентификатор запроса из контекста
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithContext возвращает логгер с полями, сохраненными в контексте запроса:
+// идентификатором запроса и, если запрос трассируется, идентификатором трассы
+func (l *ColorfulLogger) WithContext(ctx context.Context) *ColorfulLogger {
+	if ctx == nil {
+		return l
+	}
+
+	fields := Fields{}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsSampled() {
+		fields["trace_id"] = spanCtx.TraceID().String()
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields)
+}
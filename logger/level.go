@@ -0,0 +1,192 @@
+// Файл: logger/level.go
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Компоненты приложения, для которых можно задать отдельный уровень логирования
+const (
+	ComponentHandlers   = "handlers"
+	ComponentMiddleware = "middleware"
+	ComponentClient     = "client"
+	ComponentAccess     = "access"
+)
+
+// Components перечисляет известные компоненты логирования
+var Components = []string{ComponentHandlers, ComponentMiddleware, ComponentClient, ComponentAccess}
+
+// levelState хранит изменяемые во время работы уровни логирования
+type levelState struct {
+	mu         sync.RWMutex
+	base       int                    // Уровень из конфигурации
+	global     int                    // Текущий общий уровень
+	components map[string]int         // Переопределения уровней по компонентам
+	timers     map[string]*time.Timer // Таймеры автоматического возврата; ключ "" - общий уровень
+	// Поколение переопределения компонента: таймер, сработавший одновременно с новым
+	// переопределением, не должен его отменять
+	generations map[string]uint64
+}
+
+// newLevelState создает состояние уровней с уровнем из конфигурации
+func newLevelState(base int) *levelState {
+	return &levelState{
+		base:        base,
+		global:      base,
+		components:  make(map[string]int),
+		timers:      make(map[string]*time.Timer),
+		generations: make(map[string]uint64),
+	}
+}
+
+// level возвращает уровень, действующий для компонента
+func (s *levelState) level(component string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if level, ok := s.components[component]; ok {
+		return level
+	}
+	return s.global
+}
+
+// set устанавливает уровень для компонента (или общий при пустом компоненте)
+// и, если задана длительность, планирует возврат к уровню из конфигурации.
+// Новое переопределение отменяет таймер предыдущего.
+func (s *levelState) set(component string, level int, revertAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	generation := s.cancelTimer(component)
+
+	if component == "" {
+		s.global = level
+	} else {
+		s.components[component] = level
+	}
+
+	if revertAfter > 0 {
+		s.timers[component] = time.AfterFunc(revertAfter, func() { s.revert(component, generation) })
+	}
+}
+
+// cancelTimer останавливает таймер возврата компонента и начинает новое поколение
+// переопределений; вызывается под s.mu
+func (s *levelState) cancelTimer(component string) uint64 {
+	if timer, ok := s.timers[component]; ok {
+		timer.Stop()
+		delete(s.timers, component)
+	}
+	s.generations[component]++
+	return s.generations[component]
+}
+
+// revert выполняет возврат по таймеру, если переопределение не было заменено после его запуска
+func (s *levelState) revert(component string, generation uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generations[component] != generation {
+		return
+	}
+	s.restore(component)
+}
+
+// setBase меняет уровень из конфигурации; текущий общий уровень меняется,
+// только если он не переопределен временно
+func (s *levelState) setBase(level int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, overridden := s.timers[""]; !overridden && s.global == s.base {
+		s.global = level
+	}
+	s.base = level
+}
+
+// reset возвращает уровень компонента (или общий) к значению из конфигурации
+func (s *levelState) reset(component string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancelTimer(component)
+	s.restore(component)
+}
+
+// restore снимает переопределение: компонент снова использует общий уровень, а общий
+// уровень возвращается к значению из конфигурации; вызывается под s.mu
+func (s *levelState) restore(component string) {
+	delete(s.timers, component)
+	if component == "" {
+		s.global = s.base
+	} else {
+		delete(s.components, component)
+	}
+}
+
+// LevelName возвращает строковое имя уровня логирования
+func LevelName(level int) string {
+	return levelNames[level]
+}
+
+// IsValidLevel проверяет, что строка является именем уровня логирования
+func IsValidLevel(level string) bool {
+	switch level {
+	case "debug", "info", "warn", "error":
+		return true
+	}
+	return false
+}
+
+// IsValidComponent проверяет, что компонент известен логгеру
+func IsValidComponent(component string) bool {
+	for _, c := range Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// Component возвращает логгер компонента, уровень которого можно менять отдельно
+func (l *ColorfulLogger) Component(name string) *ColorfulLogger {
+	derived := l.With(Fields{"component": name})
+	derived.component = name
+	return derived
+}
+
+// SetLevel меняет уровень логирования во время работы: общий при пустом компоненте
+// или для отдельного компонента. При revertAfter > 0 по истечении времени
+// переопределение снимается и уровень возвращается к значению из конфигурации.
+func (l *ColorfulLogger) SetLevel(component string, level int, revertAfter time.Duration) {
+	l.core.levels.set(component, level, revertAfter)
+}
+
+// SetBaseLevel меняет уровень логирования, заданный конфигурацией (например, при ее перезагрузке)
+func (l *ColorfulLogger) SetBaseLevel(level int) {
+	l.core.levels.setBase(level)
+}
+
+// ResetLevel возвращает общий уровень или уровень компонента к значению из конфигурации
+func (l *ColorfulLogger) ResetLevel(component string) {
+	l.core.levels.reset(component)
+}
+
+// Level возвращает текущий уровень для компонента (общий при пустом компоненте)
+func (l *ColorfulLogger) Level(component string) int {
+	return l.core.levels.level(component)
+}
+
+// Levels возвращает текущий общий уровень и переопределения по компонентам
+func (l *ColorfulLogger) Levels() (string, map[string]string) {
+	s := l.core.levels
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	components := make(map[string]string, len(s.components))
+	for component, level := range s.components {
+		components[component] = LevelName(level)
+	}
+	return LevelName(s.global), components
+}
@@ -0,0 +1,57 @@
+// Файл: logger/level_test.go
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewOverrideReplacesRevertTimer(t *testing.T) {
+	s := newLevelState(INFO)
+
+	s.set("", DEBUG, 20*time.Millisecond)
+	s.set("", WARN, time.Hour)
+	time.Sleep(60 * time.Millisecond)
+
+	if got := s.level(""); got != WARN {
+		t.Fatalf("таймер первого переопределения вернул уровень %s, ожидался warn", LevelName(got))
+	}
+	if len(s.timers) != 1 {
+		t.Fatalf("ожидался один таймер, получено %d", len(s.timers))
+	}
+	s.reset("")
+}
+
+func TestStaleRevertIgnored(t *testing.T) {
+	s := newLevelState(INFO)
+
+	// Таймер первого переопределения сработал, но ждет блокировку, пока задается второе
+	s.set(ComponentClient, DEBUG, 0)
+	stale := s.generations[ComponentClient]
+	s.set(ComponentClient, ERROR, 0)
+	s.revert(ComponentClient, stale)
+
+	if got := s.level(ComponentClient); got != ERROR {
+		t.Fatalf("устаревший таймер снял переопределение: уровень %s, ожидался error", LevelName(got))
+	}
+
+	s.revert(ComponentClient, s.generations[ComponentClient])
+	if got := s.level(ComponentClient); got != INFO {
+		t.Fatalf("после возврата уровень %s, ожидался общий info", LevelName(got))
+	}
+}
+
+func TestRevertRestoresConfiguredLevel(t *testing.T) {
+	s := newLevelState(INFO)
+
+	s.set("", DEBUG, 20*time.Millisecond)
+	s.setBase(WARN)
+	time.Sleep(60 * time.Millisecond)
+
+	if got := s.level(""); got != WARN {
+		t.Fatalf("после возврата уровень %s, ожидался уровень из конфигурации warn", LevelName(got))
+	}
+	if len(s.timers) != 0 {
+		t.Fatalf("таймер не удален после срабатывания")
+	}
+}
@@ -2,24 +2,57 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"auth-service/config"
+
+	"github.com/mattn/go-isatty"
 )
 
-// ColorfulLogger представляет логгер с цветными выводами
+// Fields содержит структурированные поля записи лога
+type Fields map[string]interface{}
+
+// ColorfulLogger представляет структурированный логгер.
+// Цвета используются только при выводе в терминал.
 type ColorfulLogger struct {
-	infoLogger  *log.Logger
-	warnLogger  *log.Logger
-	errorLogger *log.Logger
-	debugLogger *log.Logger
-	logLevel    int
+	core      *loggerCore
+	fields    Fields
+	component string
+}
+
+// loggerCore содержит общее состояние логгера, разделяемое между его производными
+type loggerCore struct {
+	mu     sync.Mutex
+	sinks  []sink
+	format string
+	levels *levelState
+}
+
+// sink описывает одно место назначения записей лога
+type sink struct {
+	w     io.Writer
+	level int // Собственный уровень; inheritLevel - общий уровень логгера
+	color bool
+}
+
+// levelWriter реализуют места назначения, учитывающие уровень записи (например, syslog)
+type levelWriter interface {
+	io.Writer
+	WriteLevel(level int, p []byte) error
 }
 
+// inheritLevel означает, что место назначения использует общий уровень логгера
+const inheritLevel = -1
+
 const (
 	// Уровни логирования
 	DEBUG = iota
@@ -28,6 +61,12 @@ const (
 	ERROR
 )
 
+// Форматы записей лога
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
 // Цветовые коды ANSI
 const (
 	colorReset  = "\033[0m"
@@ -37,100 +76,312 @@ const (
 	colorBlue   = "\033[34m"
 )
 
-// NewColorfulLogger создает новый экземпляр логгера
-func NewColorfulLogger(cfg *config.Config) *ColorfulLogger {
-	logDir := "logs"
-	if _, err := os.Stat(logDir); os.IsNotExist(err) {
-		os.Mkdir(logDir, 0755)
+var levelNames = map[int]string{
+	DEBUG: "debug",
+	INFO:  "info",
+	WARN:  "warn",
+	ERROR: "error",
+}
+
+var levelColors = map[int]string{
+	DEBUG: colorBlue,
+	INFO:  colorGreen,
+	WARN:  colorYellow,
+	ERROR: colorRed,
+}
+
+// NewColorfulLogger создает новый экземпляр логгера с местами назначения из конфигурации
+func NewColorfulLogger(cfg *config.Config) (*ColorfulLogger, error) {
+	format := FormatLogfmt
+	if cfg.LogFormat == FormatJSON {
+		format = FormatJSON
 	}
 
-	// Создаем файл для логов аутентификации
-	authLogFile, err := os.OpenFile(
-		filepath.Join(logDir, "authka.log"),
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
-		0666,
-	)
-	if err != nil {
-		log.Fatalf("Не удалось открыть файл лога authka.log: %v", err)
+	core := &loggerCore{
+		format: format,
+		levels: newLevelState(ParseLevel(cfg.LogLevel)),
+	}
+
+	for _, sinkCfg := range cfg.LogSinks {
+		s, err := newSink(sinkCfg)
+		if err != nil {
+			core.close()
+			return nil, err
+		}
+		core.sinks = append(core.sinks, s)
+	}
+
+	return &ColorfulLogger{core: core}, nil
+}
+
+// newSink создает место назначения логов по его конфигурации
+func newSink(cfg config.LogSinkConfig) (sink, error) {
+	s := sink{level: inheritLevel}
+	if cfg.Level != "" {
+		s.level = ParseLevel(cfg.Level)
+	}
+
+	switch cfg.Type {
+	case config.SinkStdout:
+		s.w = os.Stdout
+		s.color = isatty.IsTerminal(os.Stdout.Fd())
+	case config.SinkFile:
+		file, err := NewRotatingFile(cfg.Path, cfg.RotationConfig)
+		if err != nil {
+			return s, err
+		}
+		s.w = file
+	case config.SinkSyslog:
+		w, err := newSyslogWriter(cfg)
+		if err != nil {
+			return s, fmt.Errorf("не удалось подключиться к syslog: %w", err)
+		}
+		s.w = w
+	default:
+		return s, fmt.Errorf("неизвестный тип места назначения логов: %q", cfg.Type)
+	}
+
+	return s, nil
+}
+
+// Close закрывает все места назначения логгера
+func (l *ColorfulLogger) Close() error {
+	return l.core.close()
+}
+
+// close закрывает места назначения, поддерживающие закрытие
+func (c *loggerCore) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for _, s := range c.sinks {
+		if closer, ok := s.w.(io.Closer); ok && s.w != os.Stdout {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// effectiveLevel возвращает уровень, действующий для места назначения и компонента
+func (c *loggerCore) effectiveLevel(s sink, component string) int {
+	if s.level == inheritLevel {
+		return c.levels.level(component)
 	}
+	return s.level
+}
 
-	logLevel := INFO // По умолчанию
+// WithWriter возвращает логгер с теми же форматом и уровнями,
+// записывающий только в указанный writer
+func (l *ColorfulLogger) WithWriter(w io.Writer) *ColorfulLogger {
+	return &ColorfulLogger{
+		core: &loggerCore{
+			sinks:  []sink{{w: w, level: inheritLevel}},
+			format: l.core.format,
+			levels: l.core.levels,
+		},
+		fields:    l.fields,
+		component: l.component,
+	}
+}
 
-	// Определение уровня логирования из конфигурации
-	switch cfg.LogLevel {
+// ParseLevel преобразует строковое имя уровня в константу уровня логирования
+func ParseLevel(level string) int {
+	switch strings.ToLower(level) {
 	case "debug":
-		logLevel = DEBUG
-	case "info":
-		logLevel = INFO
+		return DEBUG
 	case "warn":
-		logLevel = WARN
+		return WARN
 	case "error":
-		logLevel = ERROR
+		return ERROR
+	default:
+		return INFO
 	}
+}
 
-	return &ColorfulLogger{
-		infoLogger:  log.New(authLogFile, fmt.Sprintf("%s[INFO]%s ", colorGreen, colorReset), log.Ldate|log.Ltime),
-		warnLogger:  log.New(authLogFile, fmt.Sprintf("%s[WARN]%s ", colorYellow, colorReset), log.Ldate|log.Ltime),
-		errorLogger: log.New(authLogFile, fmt.Sprintf("%s[ERROR]%s ", colorRed, colorReset), log.Ldate|log.Ltime),
-		debugLogger: log.New(authLogFile, fmt.Sprintf("%s[DEBUG]%s ", colorBlue, colorReset), log.Ldate|log.Ltime),
-		logLevel:    logLevel,
+// With возвращает производный логгер с дополнительными полями
+func (l *ColorfulLogger) With(fields Fields) *ColorfulLogger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
 	}
+	return &ColorfulLogger{core: l.core, fields: merged, component: l.component}
 }
 
 // Debug логирует отладочные сообщения
 func (l *ColorfulLogger) Debug(format string, v ...interface{}) {
-	if l.logLevel <= DEBUG {
-		l.debugLogger.Printf(format, v...)
-	}
+	l.log(DEBUG, format, v...)
 }
 
 // Info логирует информационные сообщения
 func (l *ColorfulLogger) Info(format string, v ...interface{}) {
-	if l.logLevel <= INFO {
-		l.infoLogger.Printf(format, v...)
-	}
+	l.log(INFO, format, v...)
 }
 
 // Warn логирует предупреждения
 func (l *ColorfulLogger) Warn(format string, v ...interface{}) {
-	if l.logLevel <= WARN {
-		l.warnLogger.Printf(format, v...)
-	}
+	l.log(WARN, format, v...)
 }
 
 // Error логирует ошибки
 func (l *ColorfulLogger) Error(format string, v ...interface{}) {
-	if l.logLevel <= ERROR {
-		l.errorLogger.Printf(format, v...)
-	}
+	l.log(ERROR, format, v...)
 }
 
 // LogRequest логирует информацию о HTTP запросе
 func (l *ColorfulLogger) LogRequest(method, path, ip string, status int, duration time.Duration) {
-	var statusColor string
-
-	if status >= 200 && status < 300 {
-		statusColor = colorGreen
-	} else if status >= 300 && status < 400 {
-		statusColor = colorBlue
-	} else if status >= 400 && status < 500 {
-		statusColor = colorYellow
+	entry := l.With(Fields{
+		"method":     method,
+		"route":      path,
+		"status":     status,
+		"latency_ms": float64(duration.Microseconds()) / 1000,
+		"client_ip":  ip,
+	})
+
+	if status >= 400 {
+		entry.Warn("HTTP запрос")
 	} else {
-		statusColor = colorRed
+		entry.Info("HTTP запрос")
+	}
+}
+
+// log форматирует запись, маскирует в ней секреты и записывает ее
+// во все места назначения с подходящим уровнем
+func (l *ColorfulLogger) log(level int, format string, v ...interface{}) {
+	if !l.enabled(level) {
+		return
 	}
 
-	logMessage := fmt.Sprintf("%s %s %s%d%s %s %s",
-		method,
-		path,
-		statusColor,
-		status,
-		colorReset,
-		duration.String(),
-		ip)
+	msg := format
+	if len(v) > 0 {
+		msg = fmt.Sprintf(format, v...)
+	}
+	msg = Redact(msg)
+	fields := redactFields(l.fields)
+	now := time.Now()
 
-	if status >= 400 {
-		l.Warn(logMessage)
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	for _, s := range l.core.sinks {
+		if l.core.effectiveLevel(s, l.component) > level {
+			continue
+		}
+
+		var line []byte
+		if l.core.format == FormatJSON {
+			line = encodeJSON(now, level, msg, fields)
+		} else {
+			line = encodeLogfmt(now, level, msg, fields, s.color)
+		}
+
+		if lw, ok := s.w.(levelWriter); ok {
+			lw.WriteLevel(level, line)
+		} else {
+			s.w.Write(line)
+		}
+	}
+}
+
+// enabled проверяет, попадет ли запись с таким уровнем хотя бы в одно место назначения
+func (l *ColorfulLogger) enabled(level int) bool {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	for _, s := range l.core.sinks {
+		if l.core.effectiveLevel(s, l.component) <= level {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys возвращает ключи полей в детерминированном порядке
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// encodeJSON кодирует запись лога в одну строку JSON
+func encodeJSON(t time.Time, level int, msg string, fields Fields) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSONValue(&buf, t.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSONValue(&buf, levelNames[level])
+	buf.WriteString(`,"msg":`)
+	writeJSONValue(&buf, msg)
+	for _, k := range sortedKeys(fields) {
+		buf.WriteByte(',')
+		writeJSONValue(&buf, k)
+		buf.WriteByte(':')
+		writeJSONValue(&buf, fields[k])
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// writeJSONValue записывает значение в JSON, подменяя ошибки их текстом
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
+
+// encodeLogfmt кодирует запись лога в формате logfmt
+func encodeLogfmt(t time.Time, level int, msg string, fields Fields, color bool) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("time=")
+	buf.WriteString(t.Format(time.RFC3339Nano))
+	buf.WriteString(" level=")
+	if color {
+		buf.WriteString(levelColors[level])
+		buf.WriteString(levelNames[level])
+		buf.WriteString(colorReset)
 	} else {
-		l.Info(logMessage)
+		buf.WriteString(levelNames[level])
+	}
+	buf.WriteString(" msg=")
+	buf.WriteString(logfmtValue(msg))
+	for _, k := range sortedKeys(fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(logfmtValue(fields[k]))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// logfmtValue форматирует значение, экранируя его при необходимости
+func logfmtValue(v interface{}) string {
+	var s string
+	switch val := v.(type) {
+	case string:
+		s = val
+	case error:
+		s = val.Error()
+	case time.Duration:
+		s = val.String()
+	default:
+		s = fmt.Sprint(val)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
 	}
+	return s
 }
@@ -0,0 +1,146 @@
+// Файл: logger/redact.go
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue заменяет замаскированные секреты в логах
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys содержит имена полей, значения которых никогда не пишутся в лог
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"passwd":        true,
+	"new_password":  true,
+	"token":         true,
+	"jwt_token":     true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"api_key":       true,
+	"apikey":        true,
+	"x_api_key":     true,
+	"secret":        true,
+	"secret_key":    true,
+	"client_secret": true,
+	"cookie":        true,
+	"set_cookie":    true,
+}
+
+// sensitiveKeyPattern перечисляет чувствительные имена для поиска внутри строк
+const sensitiveKeyPattern = `password|passwd|new_password|token|jwt_token|access_token|refresh_token|` +
+	`authorization|api[_-]?key|x-api-key|secret|secret_key|client_secret|cookie|set-cookie`
+
+var (
+	// JWT и его префиксы: заголовок JWT всегда начинается с base64("{\"")
+	jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]*){0,2}`)
+
+	// Значения схем авторизации в заголовках: "Bearer <token>", "Basic <credentials>"
+	authSchemePattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+
+	// Поля JSON: "password": "..."
+	jsonFieldPattern = regexp.MustCompile(`(?i)("(?:` + sensitiveKeyPattern + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	// Пары ключ=значение и ключ: значение в query-строках, logfmt и заголовках
+	keyValuePattern = regexp.MustCompile(`(?i)\b(` + sensitiveKeyPattern + `)(\s*[=:]\s*)([^\s&",;]+)`)
+)
+
+// isSensitiveKey проверяет, относится ли имя поля к секретам
+func isSensitiveKey(key string) bool {
+	normalized := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	return sensitiveKeys[normalized]
+}
+
+// Redact маскирует секреты в произвольной строке
+func Redact(s string) string {
+	s = jsonFieldPattern.ReplaceAllString(s, `${1}"`+RedactedValue+`"`)
+	s = authSchemePattern.ReplaceAllString(s, "${1} "+RedactedValue)
+	s = keyValuePattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := keyValuePattern.FindStringSubmatch(match)
+		// Значение со схемой авторизации уже замаскировано authSchemePattern
+		if parts[3] == RedactedValue || strings.EqualFold(parts[3], "bearer") || strings.EqualFold(parts[3], "basic") {
+			return match
+		}
+		return parts[1] + parts[2] + RedactedValue
+	})
+	return jwtPattern.ReplaceAllString(s, RedactedValue)
+}
+
+// redactFields возвращает копию полей с замаскированными секретами
+func redactFields(fields Fields) Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make(Fields, len(fields))
+	for k, v := range fields {
+		if isSensitiveKey(k) {
+			redacted[k] = RedactedValue
+			continue
+		}
+		redacted[k] = redactValue(v)
+	}
+	return redacted
+}
+
+// redactValue маскирует секреты в значении поля
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return Redact(val)
+	case error:
+		return Redact(val.Error())
+	case Fields:
+		return redactFields(val)
+	case map[string]interface{}:
+		return map[string]interface{}(redactFields(val))
+	case map[string]string:
+		redacted := make(map[string]string, len(val))
+		for k, s := range val {
+			if isSensitiveKey(k) {
+				redacted[k] = RedactedValue
+			} else {
+				redacted[k] = Redact(s)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(val))
+		for i, item := range val {
+			redacted[i] = redactValue(item)
+		}
+		return redacted
+	default:
+		return redactComposite(v)
+	}
+}
+
+// redactComposite маскирует секреты в структурах, картах и срезах других типов. Значение
+// приводится к виду JSON, чтобы поля структур проверялись по своим json-именам так же,
+// как поля записи. Значения, которые не удается разобрать, заменяются целиком.
+func redactComposite(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, fmt.Stringer, json.Marshaler:
+		return v
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return v
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return RedactedValue
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return RedactedValue
+	}
+	return redactValue(generic)
+}
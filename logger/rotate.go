@@ -0,0 +1,73 @@
+// Файл: logger/rotate.go
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"auth-service/config"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// RotatingFile представляет файл лога с ротацией по размеру и времени
+type RotatingFile struct {
+	*lumberjack.Logger
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRotatingFile открывает файл лога с заданными настройками ротации
+func NewRotatingFile(path string, cfg config.RotationConfig) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог лога %s: %w", path, err)
+	}
+
+	// lumberjack открывает файл лениво, поэтому проверяем доступность заранее
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл лога %s: %w", path, err)
+	}
+	file.Close()
+
+	rf := &RotatingFile{
+		Logger: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		},
+		stop: make(chan struct{}),
+	}
+
+	if interval := cfg.RotateInterval.Std(); interval > 0 {
+		go rf.rotateEvery(interval)
+	}
+
+	return rf, nil
+}
+
+// rotateEvery выполняет ротацию файла с заданным интервалом
+func (rf *RotatingFile) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rf.Rotate()
+		case <-rf.stop:
+			return
+		}
+	}
+}
+
+// Close останавливает периодическую ротацию и закрывает файл
+func (rf *RotatingFile) Close() error {
+	rf.stopOnce.Do(func() { close(rf.stop) })
+	return rf.Logger.Close()
+}
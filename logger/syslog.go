@@ -0,0 +1,53 @@
+//go:build !windows && !plan9
+
+// Файл: logger/syslog.go
+package logger
+
+import (
+	"log/syslog"
+
+	"auth-service/config"
+)
+
+// syslogWriter передает записи в syslog с приоритетом, соответствующим уровню
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+// newSyslogWriter подключается к локальному или удаленному syslog
+func newSyslogWriter(cfg config.LogSinkConfig) (levelWriter, error) {
+	tag := cfg.Tag
+	if tag == "" {
+		tag = "auth-service"
+	}
+	w, err := syslog.Dial(cfg.Network, cfg.Address, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{w: w}, nil
+}
+
+// Write записывает сообщение с приоритетом info
+func (s *syslogWriter) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+// WriteLevel записывает сообщение с приоритетом, соответствующим уровню лога
+func (s *syslogWriter) WriteLevel(level int, p []byte) error {
+	msg := string(p)
+	switch level {
+	case DEBUG:
+		return s.w.Debug(msg)
+	case WARN:
+		return s.w.Warning(msg)
+	case ERROR:
+		return s.w.Err(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+// Close закрывает соединение с syslog
+func (s *syslogWriter) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+// Файл: logger/syslog_unsupported.go
+package logger
+
+import (
+	"errors"
+
+	"auth-service/config"
+)
+
+// newSyslogWriter сообщает, что syslog недоступен на этой платформе
+func newSyslogWriter(cfg config.LogSinkConfig) (levelWriter, error) {
+	return nil, errors.New("syslog не поддерживается на этой платформе")
+}
@@ -1,21 +1,36 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
+	"auth-service/audit"
+	"auth-service/client"
 	"auth-service/config"
 	"auth-service/docs"
 	"auth-service/handlers"
+	"auth-service/health"
 	"auth-service/logger"
+	"auth-service/metrics"
 	"auth-service/middleware"
+	"auth-service/password"
+	"auth-service/problem"
+	"auth-service/ratelimit"
+	"auth-service/redisconn"
+	"auth-service/signing"
+	"auth-service/state"
+	"auth-service/tracing"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 )
 
 // @title Auth Service API
@@ -35,47 +50,207 @@ func generateSecretKey() string {
 }
 
 func main() {
+	if handled, code := runCommand(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
+	// Процесс завершается только после возврата из run, когда отложенные функции
+	// уже сбросили логи, журнал аудита и спаны
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run запускает сервис и возвращает ошибку запуска или работы серверов
+func run() error {
 	// Загрузка конфигурации
-	cfg, err := config.LoadConfig("config.json")
+	cfg, _, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки конфигурации: %w", err)
+	}
+	logger, err := logger.NewColorfulLogger(cfg)
+	if err != nil {
+		return fmt.Errorf("ошибка инициализации логгера: %w", err)
+	}
+	defer logger.Close()
+
+	// Трассировка OpenTelemetry; оставшиеся спаны отправляются до закрытия логов
+	shutdownTracing, err := tracing.Setup(cfg.Tracing, cfg.ServiceName)
+	if err != nil {
+		return fmt.Errorf("ошибка инициализации трассировки: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Ошибка отправки спанов трассировки: %v", err)
+		}
+	}()
+
+	accessLogger, accessLogCloser, err := middleware.NewAccessLogger(logger, cfg.AccessLog)
 	if err != nil {
-		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+		return fmt.Errorf("ошибка инициализации журнала запросов: %w", err)
 	}
-	logger := logger.NewColorfulLogger(cfg)
+	defer accessLogCloser.Close()
+
+	auditLog, err := audit.Open(cfg.AuditLogPath)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия журнала аудита: %w", err)
+	}
+	defer auditLog.Close()
 
 	// if !cfg.LogLevel {
 	// 	gin.SetMode(gin.ReleaseMode)
 	// }
 
-	// И нициализация роутера Gin
-	r := gin.Default()
+	// Ключи подписи: асимметричный ключ из signing.key_file или общий секрет jwt_secret
+	var keys *signing.Keys
+	if cfg.Signing.KeyFile != "" {
+		if keys, err = signing.Load(cfg.Signing); err != nil {
+			return fmt.Errorf("ошибка загрузки ключей подписи: %w", err)
+		}
+		logger.Info("Токены подписываются алгоритмом %s, открытые ключи публикуются на /.well-known/jwks.json", keys.Algorithm)
+	} else {
+		secretKey := cfg.JWTSecret
+		if secretKey == "" {
+			secretKey = generateSecretKey()
+			logger.Warn("Секрет подписи JWT не задан (jwt_secret): сгенерирован временный, токены станут недействительны после перезапуска")
+		}
+		keys = signing.NewHMAC(secretKey)
+	}
 
 	// Инициализация контекста приложения
-	secretKey := generateSecretKey()
 	appCtx := &handlers.AppContext{
-		Config:    cfg,
-		SecretKey: secretKey,
-		Algorithm: "HS256",
-		TokenTTL:  time.Hour * 24 * 7, // 7 дней
+		Keys:      keys,
+		Passwords: password.New(cfg.PasswordHashing),
 		Logger:    logger,
+		Audit:     auditLog,
 	}
+	appCtx.SetConfig(cfg)
 
-	// Настройка Swagger
+	// Проверки зависимостей для /readyz
+	appCtx.Health = health.NewRegistry()
+	appCtx.Health.Register("backend_api", func(ctx context.Context) error {
+		return client.NewAPIClient(appCtx.Config(), logger).Ping(ctx)
+	})
+	appCtx.Health.Register("signing_keys", appCtx.CheckKeys)
+
+	// Redis используется, только если задан redis.address
+	var redisClient *redis.Client
+	if cfg.Redis.Address != "" {
+		redisClient = redisconn.New(cfg.Redis)
+		defer redisClient.Close()
+		appCtx.Health.Register("redis", redisconn.Ping(redisClient))
+	}
+
+	// Хранилище ограничителя частоты; правила и rate_limit.enabled применяются без перезапуска
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimit.Backend == config.RateLimitRedis {
+		rateLimitStore = ratelimit.NewRedisStore(redisClient, cfg.Redis.KeyPrefix)
+	} else {
+		memoryStore := ratelimit.NewMemoryStore()
+		err := metrics.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "auth",
+			Name:      "rate_limit_buckets",
+			Help:      "Число корзин ограничителя частоты в памяти.",
+		}, func() float64 { return float64(memoryStore.Len()) }))
+		if err != nil {
+			logger.Warn("Ошибка регистрации метрики ограничителя частоты: %v", err)
+		}
+		rateLimitStore = memoryStore
+	}
+
+	// Состояние сессий: отозванные токены, блокировки входа и сброс кеша пользователей
+	if cfg.SessionState.Backend == config.SessionStateRedis {
+		appCtx.Sessions = state.NewRedisStore(redisClient, cfg.Redis.KeyPrefix)
+	} else {
+		appCtx.Sessions = state.NewMemoryStore()
+	}
+	defer appCtx.Sessions.Close()
+	appCtx.Users = state.NewUserCache(cfg.SessionState.UserCacheSize)
+	appCtx.Sessions.Subscribe(appCtx.Users.Delete)
+	err = metrics.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "auth",
+		Name:      "user_cache_entries",
+		Help:      "Число пользователей в локальном кеше реплики.",
+	}, func() float64 { return float64(appCtx.Users.Len()) }))
+	if err != nil {
+		logger.Warn("Ошибка регистрации метрики кеша пользователей: %v", err)
+	}
+
+	// Публичный роутер: только пользовательские эндпоинты аутентификации и проверки состояния
+	r := gin.New()
+	r.Use(
+		gin.Recovery(),
+		middleware.RequestID(),
+		middleware.Tracing(),
+		middleware.AccessLog(appCtx, accessLogger),
+		middleware.Metrics(),
+		middleware.RateLimit(appCtx, rateLimitStore),
+	)
+	r.NoRoute(func(c *gin.Context) { problem.Abort(c, problem.CodeNotFound) })
+
+	// Swagger UI обслуживается административным listener, но описывает публичный API
 	docs.SwaggerInfo.Host = fmt.Sprintf("localhost:%d", cfg.ServerPort)
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Проверки жизнеспособности и готовности
+	r.GET("/healthz", handlers.Liveness())
+	r.GET("/readyz", handlers.Readiness(appCtx))
+
+	// Открытые ключи для локальной проверки токенов другими сервисами
+	r.GET("/.well-known/jwks.json", handlers.JWKS(appCtx))
 
 	// Настройка роутов
 	r.POST("/login", handlers.Login(appCtx))
 	r.POST("/token/create", handlers.CreateToken(appCtx))
-	r.POST("/token/verify", middleware.AuthMiddleware(appCtx), handlers.VerifyToken(appCtx))
-	r.POST("/token/refresh", middleware.AuthMiddleware(appCtx), handlers.RefreshToken(appCtx))
-	r.POST("/logout", middleware.AuthMiddleware(appCtx), handlers.Logout(appCtx))
+	r.POST("/token/verify", middleware.AuthMiddleware(appCtx), middleware.CSRF(appCtx), handlers.VerifyToken(appCtx))
+	r.POST("/token/refresh", middleware.RefreshAuth(appCtx), middleware.CSRF(appCtx), handlers.RefreshToken(appCtx))
+	r.POST("/logout", middleware.AuthMiddleware(appCtx), middleware.CSRF(appCtx), handlers.Logout(appCtx))
 
-	// Запуск сервера
-	serverAddr := fmt.Sprintf(":%d", cfg.ServerPort)
-	logger.Debug("Сервер запущен на http://localhost%s", serverAddr)
-	logger.Debug("Swagger UI доступен по адресу: http://localhost:%d/swagger/index.html", cfg.ServerPort)
+	// Перезагрузка конфигурации по SIGHUP и при изменении файла
+	reloader := &configReloader{args: os.Args[1:], appCtx: appCtx}
+	handleSignals(appCtx, reloader)
+	stopWatch := make(chan struct{})
+	go reloader.watch(stopWatch)
+	defer close(stopWatch)
+
+	// Публичный сервер
+	srv := newHTTPServer(cfg, r)
+	scheme := "http"
+	if cfg.TLS.Enabled {
+		if err := enableTLS(srv, cfg.TLS, "tls_certificate", appCtx, stopWatch); err != nil {
+			return fmt.Errorf("ошибка настройки TLS: %w", err)
+		}
+		scheme = "https"
+	}
 
-	if err := r.Run(serverAddr); err != nil {
-		logger.Error("Ошибка запуска сервера: %v", err)
+	// Административный сервер: административные эндпоинты, метрики, pprof и Swagger UI.
+	// Таймаут записи отключен, так как профили pprof передаются дольше обычных ответов.
+	adminSrv := newHTTPServer(cfg, newAdminRouter(appCtx, accessLogger))
+	adminSrv.Addr = cfg.AdminListener.Address
+	adminSrv.WriteTimeout = 0
+	adminScheme := "http"
+	if cfg.AdminListener.TLS.Enabled {
+		if err := enableTLS(adminSrv, cfg.AdminListener.TLS, "admin_tls_certificate", appCtx, stopWatch); err != nil {
+			return fmt.Errorf("ошибка настройки TLS административного listener: %w", err)
+		}
+		adminScheme = "https"
+	}
+
+	logger.Debug("Сервер запущен на %s://localhost%s", scheme, srv.Addr)
+	logger.Debug("Административный сервер запущен на %s://%s, Swagger UI: /swagger/index.html", adminScheme, adminSrv.Addr)
+
+	// Логи и журнал аудита сбрасываются отложенными Close после возврата из serve
+	err = serve(appCtx,
+		&listener{name: "публичный сервер", address: srv.Addr, srv: srv},
+		&listener{name: "административный сервер", address: cfg.AdminListener.Address, srv: adminSrv},
+	)
+	if err != nil {
+		logger.Error("Ошибка работы сервера: %v", err)
+		return fmt.Errorf("ошибка работы сервера: %w", err)
 	}
+	return nil
 }
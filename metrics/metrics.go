@@ -0,0 +1,188 @@
+// Файл: metrics/metrics.go
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace задает общий префикс имен метрик сервиса
+const namespace = "auth"
+
+// Результаты попыток входа
+const (
+	LoginSuccess     = "success"
+	LoginUnknownUser = "unknown_user"
+	LoginBadPassword = "bad_password"
+	LoginLocked      = "locked"
+	LoginDisabled    = "disabled"
+	LoginResetNeeded = "password_reset_required"
+)
+
+// Операции с токенами
+const (
+	TokenIssued    = "issued"
+	TokenRefreshed = "refreshed"
+	TokenRevoked   = "revoked"
+)
+
+// Причины отказа при проверке токена
+const (
+	ValidationMalformed     = "malformed"
+	ValidationBadSignature  = "bad_signature"
+	ValidationExpired       = "expired"
+	ValidationMissingClaim  = "missing_claim"
+	ValidationUnknownUser   = "unknown_user"
+	ValidationTokenMismatch = "token_mismatch"
+	ValidationRevoked       = "revoked"
+	ValidationDisabled      = "disabled"
+	ValidationResetNeeded   = "password_reset_required"
+)
+
+// registry содержит только метрики сервиса и стандартные метрики процесса
+var registry = prometheus.NewRegistry()
+
+var (
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Попытки входа по результату.",
+	}, []string{"outcome"})
+
+	tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_total",
+		Help:      "Выпущенные, обновленные и отозванные токены.",
+	}, []string{"operation"})
+
+	validationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_validation_failures_total",
+		Help:      "Отказы при проверке токена по причине.",
+	}, []string{"reason"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Длительность обработки HTTP запросов.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	backendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "backend_request_duration_seconds",
+		Help:      "Длительность запросов к API базы данных по методу и статусу ответа.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint", "status"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Запросы, отклоненные ограничением частоты, по правилу.",
+	}, []string{"rule"})
+
+	userCache = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_cache_requests_total",
+		Help:      "Обращения к кешу пользователей по результату.",
+	}, []string{"result"})
+
+	rateLimitErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_store_errors_total",
+		Help:      "Ошибки хранилища ограничителя частоты.",
+	})
+
+	pepperFallbacks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "password_pepper_fallbacks_total",
+		Help:      "Успешные проверки пароля по хешу, созданному без перца.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		logins, tokens, validationFailures, httpDuration, backendDuration,
+		rateLimited, rateLimitErrors, userCache, pepperFallbacks,
+	)
+
+	// Результаты заранее инициализируются нулями, чтобы ряды были видны до первого события
+	for _, outcome := range []string{LoginSuccess, LoginUnknownUser, LoginBadPassword, LoginLocked, LoginDisabled, LoginResetNeeded} {
+		logins.WithLabelValues(outcome)
+	}
+	for _, operation := range []string{TokenIssued, TokenRefreshed, TokenRevoked} {
+		tokens.WithLabelValues(operation)
+	}
+}
+
+// Handler возвращает обработчик, отдающий метрики в формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Register регистрирует дополнительные коллекторы (например, размеры кешей)
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveLogin учитывает попытку входа с указанным результатом
+func ObserveLogin(outcome string) {
+	logins.WithLabelValues(outcome).Inc()
+}
+
+// ObserveToken учитывает операцию с токеном
+func ObserveToken(operation string) {
+	tokens.WithLabelValues(operation).Inc()
+}
+
+// ObserveValidationFailure учитывает отказ при проверке токена
+func ObserveValidationFailure(reason string) {
+	validationFailures.WithLabelValues(reason).Inc()
+}
+
+// ObserveHTTPRequest учитывает длительность обработки HTTP запроса
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveUserCache учитывает попадание или промах кеша пользователей
+func ObserveUserCache(hit bool) {
+	if hit {
+		userCache.WithLabelValues("hit").Inc()
+	} else {
+		userCache.WithLabelValues("miss").Inc()
+	}
+}
+
+// ObserveRateLimited учитывает запрос, отклоненный правилом ограничения частоты
+func ObserveRateLimited(rule string) {
+	rateLimited.WithLabelValues(rule).Inc()
+}
+
+// ObserveRateLimitError учитывает ошибку хранилища ограничителя частоты
+func ObserveRateLimitError() {
+	rateLimitErrors.Inc()
+}
+
+// ObservePepperFallback учитывает вход по хешу пароля, созданному без перца
+func ObservePepperFallback() {
+	pepperFallbacks.Inc()
+}
+
+// ObserveBackendRequest учитывает длительность запроса к API базы данных.
+// Для сетевых ошибок status равен "error".
+func ObserveBackendRequest(endpoint, status string, duration time.Duration) {
+	backendDuration.WithLabelValues(endpoint, status).Observe(duration.Seconds())
+}
@@ -0,0 +1,91 @@
+// Файл: middleware/admin.go
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/logger"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAccess возвращает аутентификацию административного listener для указанного способа
+func AdminAccess(appCtx *handlers.AppContext, mode string) gin.HandlerFunc {
+	switch mode {
+	case config.AdminAuthMTLS:
+		return AdminClientCert(appCtx)
+	case config.AdminAuthNone:
+		// Доступ к unix сокету ограничен правами на файл
+		return func(c *gin.Context) {
+			c.Set("adminActor", "admin:socket")
+			c.Next()
+		}
+	default:
+		return AdminAuth(appCtx)
+	}
+}
+
+// AdminClientCert пропускает только запросы с клиентским сертификатом, проверенным при установке TLS соединения
+func AdminClientCert(appCtx *handlers.AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			appCtx.RequestLogger(c).Component(logger.ComponentMiddleware).
+				Warn("Отказано в административном доступе: нет проверенного клиентского сертификата")
+			problem.Abort(c, problem.CodeClientCertRequired)
+			return
+		}
+
+		cert := c.Request.TLS.VerifiedChains[0][0]
+		c.Set("adminActor", "mtls:"+cert.Subject.CommonName)
+		c.Next()
+	}
+}
+
+// AdminAuth пропускает только запросы с одним из административных токенов из конфигурации
+func AdminAuth(appCtx *handlers.AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := appCtx.RequestLogger(c).Component(logger.ComponentMiddleware)
+
+		if len(appCtx.Config().AdminTokens) == 0 {
+			log.Warn("Попытка доступа к административному эндпоинту без настроенных admin_tokens")
+			problem.Abort(c, problem.CodeAdminNotConfigured)
+			return
+		}
+
+		// Заголовок разбирается так же, как на публичных эндпоинтах
+		var token string
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			var err error
+			if token, err = parseAuthorization(authHeader); err != nil {
+				log.Warn("Отказано в административном доступе: %v", err)
+				abortUnauthorized(c, appCtx.Config().Credentials.Realm, err)
+				return
+			}
+		}
+
+		if token == "" || !isAdminToken(token, appCtx.Config().AdminTokens) {
+			log.Warn("Отказано в административном доступе: неверный токен")
+			problem.Abort(c, problem.CodeInvalidAdminToken)
+			return
+		}
+
+		// Идентифицируем администратора по отпечатку токена, не раскрывая сам токен
+		fingerprint := sha256.Sum256([]byte(token))
+		c.Set("adminActor", "admin:"+hex.EncodeToString(fingerprint[:4]))
+		c.Next()
+	}
+}
+
+// isAdminToken сравнивает токен со списком административных токенов за постоянное время
+func isAdminToken(token string, adminTokens []string) bool {
+	match := 0
+	for _, adminToken := range adminTokens {
+		match |= subtle.ConstantTimeCompare([]byte(token), []byte(adminToken))
+	}
+	return match == 1
+}
@@ -2,10 +2,10 @@
 package middleware
 
 import (
-	"net/http"
-	"strings"
+	"errors"
 
 	"auth-service/handlers"
+	"auth-service/logger"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,42 +13,73 @@ import (
 // AuthMiddleware проверяет авторизацию пользователя
 func AuthMiddleware(appCtx *handlers.AppContext) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Получаем токен из заголовка Authorization
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Отсутствует заголовок авторизации"})
-			c.Abort()
-			return
-		}
+		log := appCtx.RequestLogger(c).Component(logger.ComponentMiddleware)
+		cfg := appCtx.Config()
 
-		// Извлекаем токен из заголовка
-		var token string
-		if strings.HasPrefix(strings.ToLower(authHeader), "bearer ") {
-			token = strings.TrimPrefix(strings.ToLower(authHeader), "bearer ")
-		} else {
-			token = authHeader
+		// Получаем токен из настроенных источников: заголовка, cookie сессии или параметра запроса
+		credential, err := ExtractCredential(c.Request, cfg)
+		if err != nil {
+			if errors.Is(err, errNoCredential) {
+				log.Debug("Запрос без учетных данных")
+			} else {
+				log.Warn("Некорректные учетные данные: %v", err)
+			}
+			abortUnauthorized(c, cfg.Credentials.Realm, err)
+			return
 		}
-
-		appCtx.Logger.Debug("Проверка токена из заголовка: %s...", token[:10]+"...")
+		log.Debug("Проверка токена из источника %s", credential.Source)
 
 		// Проверяем токен напрямую через ValidateToken
-		claims, err := appCtx.ValidateToken(token)
+		claims, err := appCtx.ValidateToken(c.Request.Context(), credential.Token)
 		if err != nil {
-			appCtx.Logger.Error("Ошибка при проверке токена: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный токен: " + err.Error()})
-			c.Abort()
+			log.Error("Ошибка при проверке токена: %v", err)
+			abortInvalidToken(c, cfg.Credentials.Realm, err)
 			return
 		}
 
 		// Добавляем данные пользователя в контекст для использования в обработчиках
 		c.Set("username", claims.Username)
 		c.Set("agencyID", claims.AgencyID)
-		c.Set("token", token)
+		c.Set("token", credential.Token)
+		c.Set("tokenID", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
+		c.Set("credentialSource", credential.Source)
 
-		appCtx.Logger.Info("Успешная аутентификация пользователя: %s (Agency ID: %d)",
-			claims.Username, claims.AgencyID)
+		log.With(logger.Fields{"username": claims.Username, "agency_id": claims.AgencyID}).
+			Info("Успешная аутентификация пользователя: %s (Agency ID: %d)", claims.Username, claims.AgencyID)
 
 		// Продолжаем выполнение цепочки middleware
 		c.Next()
 	}
 }
+
+// RefreshAuth аутентифицирует запрос на обновление токена. Без заголовка Authorization
+// принимается cookie обновления, что позволяет продлить сессию после истечения cookie сессии;
+// в остальных случаях проверка выполняется как в AuthMiddleware.
+func RefreshAuth(appCtx *handlers.AppContext) gin.HandlerFunc {
+	auth := AuthMiddleware(appCtx)
+	return func(c *gin.Context) {
+		cfg := appCtx.Config()
+		refreshToken, err := c.Cookie(cfg.SessionCookies.RefreshName)
+		if !cfg.SessionCookies.Enabled || c.GetHeader("Authorization") != "" || err != nil || refreshToken == "" {
+			auth(c)
+			return
+		}
+
+		log := appCtx.RequestLogger(c).Component(logger.ComponentMiddleware)
+		claims, err := appCtx.ValidateRefreshToken(c.Request.Context(), refreshToken)
+		if err != nil {
+			log.Warn("Ошибка при проверке cookie обновления: %v", err)
+			abortInvalidToken(c, cfg.Credentials.Realm, err)
+			return
+		}
+
+		c.Set("username", claims.Username)
+		c.Set("agencyID", claims.AgencyID)
+		c.Set("refreshFamily", claims.Family)
+		c.Set("credentialSource", handlers.CredentialRefreshCookie)
+		c.Next()
+	}
+}
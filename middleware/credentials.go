@@ -0,0 +1,231 @@
+// Файл: middleware/credentials.go
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTokenLength ограничивает длину принимаемого токена
+const maxTokenLength = 8192
+
+// Коды ошибок RFC 6750 для заголовка WWW-Authenticate
+const (
+	bearerInvalidRequest = "invalid_request"
+	bearerInvalidToken   = "invalid_token"
+)
+
+// Credential содержит токен доступа, извлеченный из запроса, и его источник
+type Credential struct {
+	Token  string
+	Source string // handlers.CredentialHeader, handlers.CredentialSessionCookie, handlers.CredentialQuery или handlers.CredentialBody
+}
+
+// credentialError описывает некорректные учетные данные в запросе.
+// description передается в WWW-Authenticate и должно содержать только ASCII символы.
+type credentialError struct {
+	description string
+	message     string
+}
+
+func (e *credentialError) Error() string {
+	return e.message
+}
+
+// errNoCredential означает, что запрос не содержит учетных данных ни в одном из источников
+var errNoCredential = errors.New("отсутствуют учетные данные")
+
+// ExtractCredential извлекает токен доступа из источников cfg.Sources в порядке их перечисления.
+// Схема Bearer распознается без учета регистра, сам токен не изменяется. Передача токена
+// несколькими из включенных способов RFC 6750 (заголовок, параметр запроса, тело формы)
+// считается ошибкой (RFC 6750, раздел 2).
+func ExtractCredential(r *http.Request, cfg *config.Config) (Credential, error) {
+	sources := cfg.Credentials.Sources
+	header := ""
+	if hasSource(sources, config.CredentialSourceHeader) {
+		header = r.Header.Get("Authorization")
+	}
+	query := ""
+	if r.URL != nil && hasSource(sources, config.CredentialSourceQuery) {
+		query = r.URL.Query().Get(cfg.Credentials.QueryParam)
+	}
+	body := ""
+	if hasSource(sources, config.CredentialSourceBody) {
+		body = formToken(r, cfg.Credentials.QueryParam)
+	}
+
+	methods := 0
+	for _, value := range []string{header, query, body} {
+		if value != "" {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return Credential{}, &credentialError{
+			description: "token must be sent using a single method",
+			message:     "токен передан несколькими способами одновременно",
+		}
+	}
+
+	for _, source := range cfg.Credentials.Sources {
+		switch source {
+		case config.CredentialSourceHeader:
+			if header != "" {
+				token, err := parseAuthorization(header)
+				return Credential{Token: token, Source: handlers.CredentialHeader}, err
+			}
+		case config.CredentialSourceCookie:
+			if !cfg.SessionCookies.Enabled {
+				continue
+			}
+			if cookie, err := r.Cookie(cfg.SessionCookies.SessionName); err == nil && cookie.Value != "" {
+				if err := checkToken(cookie.Value); err != nil {
+					return Credential{}, err
+				}
+				return Credential{Token: cookie.Value, Source: handlers.CredentialSessionCookie}, nil
+			}
+		case config.CredentialSourceQuery:
+			if query != "" {
+				if err := checkToken(query); err != nil {
+					return Credential{}, err
+				}
+				return Credential{Token: query, Source: handlers.CredentialQuery}, nil
+			}
+		case config.CredentialSourceBody:
+			if body != "" {
+				if err := checkToken(body); err != nil {
+					return Credential{}, err
+				}
+				return Credential{Token: body, Source: handlers.CredentialBody}, nil
+			}
+		}
+	}
+
+	return Credential{}, errNoCredential
+}
+
+// formToken возвращает токен из тела запроса application/x-www-form-urlencoded
+// (RFC 6750, раздел 2.2). Разобранная форма сохраняется в запросе и доступна обработчику.
+func formToken(r *http.Request, param string) string {
+	if r.Method == http.MethodGet || r.Body == nil {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" {
+		return ""
+	}
+	if err := r.ParseForm(); err != nil {
+		return ""
+	}
+	return r.PostForm.Get(param)
+}
+
+// hasSource проверяет, включен ли источник учетных данных
+func hasSource(sources []string, source string) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAuthorization извлекает токен из значения заголовка Authorization.
+// Поддерживается схема Bearer в любом регистре, а также токен без схемы
+// для совместимости с существующими клиентами.
+func parseAuthorization(header string) (string, error) {
+	header = strings.TrimSpace(header)
+
+	scheme, token, hasScheme := strings.Cut(header, " ")
+	if !hasScheme && !strings.EqualFold(header, "Bearer") {
+		// Токен без схемы
+		return header, checkToken(header)
+	}
+
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", &credentialError{
+			description: "unsupported authorization scheme",
+			message:     fmt.Sprintf("неподдерживаемая схема авторизации %.20q", scheme),
+		}
+	}
+
+	token = strings.TrimLeft(token, " ")
+	if token == "" {
+		return "", &credentialError{
+			description: "missing bearer token",
+			message:     "в заголовке авторизации отсутствует токен",
+		}
+	}
+	return token, checkToken(token)
+}
+
+// checkToken проверяет, что токен соответствует синтаксису b64token (RFC 6750, раздел 2.1)
+func checkToken(token string) error {
+	if len(token) > maxTokenLength {
+		return &credentialError{
+			description: "token is too long",
+			message:     "токен слишком длинный",
+		}
+	}
+
+	padding := false
+	for i := 0; i < len(token); i++ {
+		ch := token[i]
+		switch {
+		case ch == '=':
+			padding = true
+			continue
+		case padding:
+			// После '=' допускаются только '='
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+			continue
+		case ch == '-', ch == '.', ch == '_', ch == '~', ch == '+', ch == '/':
+			continue
+		}
+		return &credentialError{
+			description: "malformed token",
+			message:     "токен содержит недопустимые символы",
+		}
+	}
+	return nil
+}
+
+// abortUnauthorized завершает запрос, в котором не удалось извлечь учетные данные.
+// По RFC 6750 без учетных данных возвращается 401 без кода ошибки,
+// а при некорректно переданном токене - 400 с кодом invalid_request.
+func abortUnauthorized(c *gin.Context, realm string, err error) {
+	var credErr *credentialError
+	if !errors.As(err, &credErr) {
+		c.Header("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", realm))
+		problem.Abort(c, problem.CodeMissingCredentials)
+		return
+	}
+
+	c.Header("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=%q, error_description=%q",
+		realm, bearerInvalidRequest, credErr.description))
+	detail := credErr.message
+	if problem.Language(c) == problem.LangEN {
+		detail = credErr.description
+	}
+	problem.AbortDetail(c, problem.CodeMalformedCredentials, detail)
+}
+
+// abortInvalidToken завершает запрос с недействительным токеном (401, invalid_token).
+// Если токен не удалось проверить из-за недоступности API, возвращается 503 без вызова.
+func abortInvalidToken(c *gin.Context, realm string, err error) {
+	code := handlers.TokenErrorCode(err)
+	if problem.Status(code) == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q, error=%q, error_description=%q",
+			realm, bearerInvalidToken, problem.Message(code, problem.LangEN)))
+	}
+	problem.Abort(c, code)
+}
@@ -0,0 +1,263 @@
+// Файл: middleware/credentials_test.go
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"auth-service/client"
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testRealm = "test-realm"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// credentialsConfig возвращает конфигурацию с указанными источниками токена и включенными cookie
+func credentialsConfig(sources ...string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Credentials = config.CredentialsConfig{Sources: sources, QueryParam: "access_token", Realm: testRealm}
+	cfg.SessionCookies.Enabled = true
+	cfg.SessionCookies.SessionName = "authka_session"
+	return cfg
+}
+
+// credentialRequest описывает, где в запросе передан токен
+type credentialRequest struct {
+	method string
+	header string
+	query  string
+	body   string
+	cookie string
+}
+
+func (r credentialRequest) build() *http.Request {
+	method := r.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	target := "/token/verify"
+	if r.query != "" {
+		target += "?access_token=" + url.QueryEscape(r.query)
+	}
+
+	var req *http.Request
+	if r.body != "" {
+		form := url.Values{"access_token": {r.body}}.Encode()
+		req = httptest.NewRequest(method, target, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	if r.header != "" {
+		req.Header.Set("Authorization", r.header)
+	}
+	if r.cookie != "" {
+		req.AddCookie(&http.Cookie{Name: "authka_session", Value: r.cookie})
+	}
+	return req
+}
+
+func TestExtractCredential(t *testing.T) {
+	allSources := []string{config.CredentialSourceHeader, config.CredentialSourceCookie, config.CredentialSourceQuery, config.CredentialSourceBody}
+	defaultSources := []string{config.CredentialSourceHeader, config.CredentialSourceCookie}
+	maxToken := strings.Repeat("a", maxTokenLength)
+	longToken := maxToken + "a"
+
+	tests := []struct {
+		name        string
+		sources     []string
+		cookiesOff  bool
+		request     credentialRequest
+		wantToken   string
+		wantSource  string
+		wantError   string // description credentialError; пусто - без ошибки
+		wantMissing bool
+	}{
+		// Схема Bearer
+		{name: "bearer", request: credentialRequest{header: "Bearer abc.def"}, wantToken: "abc.def", wantSource: handlers.CredentialHeader},
+		{name: "lowercase scheme", request: credentialRequest{header: "bearer abc"}, wantToken: "abc", wantSource: handlers.CredentialHeader},
+		{name: "uppercase scheme", request: credentialRequest{header: "BEARER abc"}, wantToken: "abc", wantSource: handlers.CredentialHeader},
+		{name: "mixed case scheme", request: credentialRequest{header: "bEaReR abc"}, wantToken: "abc", wantSource: handlers.CredentialHeader},
+		{name: "multiple spaces after scheme", request: credentialRequest{header: "Bearer     abc"}, wantToken: "abc", wantSource: handlers.CredentialHeader},
+		{name: "surrounding whitespace", request: credentialRequest{header: "  Bearer abc  "}, wantToken: "abc", wantSource: handlers.CredentialHeader},
+		{name: "token without scheme", request: credentialRequest{header: "abc.def.ghi"}, wantToken: "abc.def.ghi", wantSource: handlers.CredentialHeader},
+		{name: "all b64token characters", request: credentialRequest{header: "Bearer aZ09-._~+/=="}, wantToken: "aZ09-._~+/==", wantSource: handlers.CredentialHeader},
+		{name: "unsupported scheme", request: credentialRequest{header: "Basic dXNlcjpwYXNz"}, wantError: "unsupported authorization scheme"},
+		{name: "scheme without token", request: credentialRequest{header: "Bearer"}, wantError: "missing bearer token"},
+		{name: "scheme with only spaces", request: credentialRequest{header: "Bearer    "}, wantError: "missing bearer token"},
+
+		// Синтаксис b64token
+		{name: "space inside token", request: credentialRequest{header: "Bearer abc def"}, wantError: "malformed token"},
+		{name: "padding in the middle", request: credentialRequest{header: "Bearer ab=c"}, wantError: "malformed token"},
+		{name: "quoted token", request: credentialRequest{header: `Bearer "abc"`}, wantError: "malformed token"},
+		{name: "comma", request: credentialRequest{header: "Bearer abc,def"}, wantError: "malformed token"},
+		{name: "non-ascii", request: credentialRequest{header: "Bearer токен"}, wantError: "malformed token"},
+		{name: "control character", request: credentialRequest{header: "Bearer abc\x01"}, wantError: "malformed token"},
+		{name: "malformed cookie", request: credentialRequest{cookie: "abc!def"}, wantError: "malformed token"},
+		{name: "malformed query", sources: allSources, request: credentialRequest{query: "abc def"}, wantError: "malformed token"},
+
+		// Длина токена
+		{name: "token at length limit", request: credentialRequest{header: "Bearer " + maxToken}, wantToken: maxToken, wantSource: handlers.CredentialHeader},
+		{name: "header token over limit", request: credentialRequest{header: "Bearer " + longToken}, wantError: "token is too long"},
+		{name: "header token over limit without scheme", request: credentialRequest{header: longToken}, wantError: "token is too long"},
+		{name: "cookie token over limit", request: credentialRequest{cookie: longToken}, wantError: "token is too long"},
+		{name: "query token over limit", sources: allSources, request: credentialRequest{query: longToken}, wantError: "token is too long"},
+
+		// Один способ передачи (RFC 6750, раздел 2)
+		{name: "header, body and query", sources: allSources, request: credentialRequest{header: "Bearer a", query: "b", body: "c"}, wantError: "token must be sent using a single method"},
+		{name: "header and query", sources: allSources, request: credentialRequest{header: "Bearer a", query: "b"}, wantError: "token must be sent using a single method"},
+		{name: "header and body", sources: allSources, request: credentialRequest{header: "Bearer a", body: "c"}, wantError: "token must be sent using a single method"},
+		{name: "query and body", sources: allSources, request: credentialRequest{query: "b", body: "c"}, wantError: "token must be sent using a single method"},
+		{name: "query only", sources: allSources, request: credentialRequest{query: "b"}, wantToken: "b", wantSource: handlers.CredentialQuery},
+		{name: "body only", sources: allSources, request: credentialRequest{body: "c"}, wantToken: "c", wantSource: handlers.CredentialBody},
+		{name: "body on GET ignored", sources: allSources, request: credentialRequest{method: http.MethodGet, body: "c"}, wantMissing: true},
+		{name: "disabled query ignored", request: credentialRequest{header: "Bearer a", query: "b"}, wantToken: "a", wantSource: handlers.CredentialHeader},
+		{name: "disabled body ignored", request: credentialRequest{header: "Bearer a", body: "c"}, wantToken: "a", wantSource: handlers.CredentialHeader},
+
+		// Порядок источников и cookie
+		{name: "header before cookie", request: credentialRequest{header: "Bearer a", cookie: "b"}, wantToken: "a", wantSource: handlers.CredentialHeader},
+		{name: "cookie before header", sources: []string{config.CredentialSourceCookie, config.CredentialSourceHeader}, request: credentialRequest{header: "Bearer a", cookie: "b"}, wantToken: "b", wantSource: handlers.CredentialSessionCookie},
+		{name: "cookie when header absent", request: credentialRequest{cookie: "b"}, wantToken: "b", wantSource: handlers.CredentialSessionCookie},
+		{name: "cookie before query", sources: []string{config.CredentialSourceCookie, config.CredentialSourceQuery}, request: credentialRequest{query: "a", cookie: "b"}, wantToken: "b", wantSource: handlers.CredentialSessionCookie},
+		{name: "cookies disabled", cookiesOff: true, request: credentialRequest{cookie: "b"}, wantMissing: true},
+		{name: "no credentials", request: credentialRequest{}, wantMissing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := tt.sources
+			if sources == nil {
+				sources = defaultSources
+			}
+			cfg := credentialsConfig(sources...)
+			cfg.SessionCookies.Enabled = !tt.cookiesOff
+
+			credential, err := ExtractCredential(tt.request.build(), cfg)
+
+			switch {
+			case tt.wantMissing:
+				if !errors.Is(err, errNoCredential) {
+					t.Fatalf("ошибка %v, ожидалось отсутствие учетных данных", err)
+				}
+			case tt.wantError != "":
+				var credErr *credentialError
+				if !errors.As(err, &credErr) {
+					t.Fatalf("ошибка %v, ожидалась credentialError %q", err, tt.wantError)
+				}
+				if credErr.description != tt.wantError {
+					t.Fatalf("description %q, ожидалось %q", credErr.description, tt.wantError)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("неожиданная ошибка: %v", err)
+				}
+				if credential.Token != tt.wantToken || credential.Source != tt.wantSource {
+					t.Fatalf("получено (%.20q, %s), ожидалось (%.20q, %s)",
+						credential.Token, credential.Source, tt.wantToken, tt.wantSource)
+				}
+			}
+		})
+	}
+}
+
+func TestFormTokenKeepsForm(t *testing.T) {
+	req := credentialRequest{body: "c"}.build()
+	credential, err := ExtractCredential(req, credentialsConfig(config.CredentialSourceBody))
+	if err != nil || credential.Token != "c" {
+		t.Fatalf("получено (%q, %v), ожидался токен из тела", credential.Token, err)
+	}
+	if got := req.PostForm.Get("access_token"); got != "c" {
+		t.Fatalf("разобранная форма не сохранена в запросе: %q", got)
+	}
+}
+
+func TestWWWAuthenticate(t *testing.T) {
+	invalidToken := func(code problem.Code) string {
+		return fmt.Sprintf("Bearer realm=%q, error=%q, error_description=%q",
+			testRealm, "invalid_token", problem.Message(code, problem.LangEN))
+	}
+
+	tests := []struct {
+		name       string
+		abort      func(c *gin.Context)
+		wantStatus int
+		wantHeader string // пусто - заголовок не передается
+	}{
+		{
+			name:       "missing credentials",
+			abort:      func(c *gin.Context) { abortUnauthorized(c, testRealm, errNoCredential) },
+			wantStatus: http.StatusUnauthorized,
+			wantHeader: `Bearer realm="test-realm"`,
+		},
+		{
+			name: "malformed token",
+			abort: func(c *gin.Context) {
+				abortUnauthorized(c, testRealm, &credentialError{description: "malformed token", message: "токен содержит недопустимые символы"})
+			},
+			wantStatus: http.StatusBadRequest,
+			wantHeader: `Bearer realm="test-realm", error="invalid_request", error_description="malformed token"`,
+		},
+		{
+			name:       "invalid token",
+			abort:      func(c *gin.Context) { abortInvalidToken(c, testRealm, handlers.ErrInvalidToken) },
+			wantStatus: http.StatusUnauthorized,
+			wantHeader: invalidToken(problem.CodeInvalidToken),
+		},
+		{
+			name:       "expired token",
+			abort:      func(c *gin.Context) { abortInvalidToken(c, testRealm, handlers.ErrTokenExpired) },
+			wantStatus: http.StatusUnauthorized,
+			wantHeader: invalidToken(problem.CodeTokenExpired),
+		},
+		{
+			name:       "revoked token",
+			abort:      func(c *gin.Context) { abortInvalidToken(c, testRealm, handlers.ErrTokenRevoked) },
+			wantStatus: http.StatusUnauthorized,
+			wantHeader: invalidToken(problem.CodeTokenRevoked),
+		},
+		{
+			name:       "disabled account",
+			abort:      func(c *gin.Context) { abortInvalidToken(c, testRealm, handlers.ErrDisabled) },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "password reset required",
+			abort:      func(c *gin.Context) { abortInvalidToken(c, testRealm, handlers.ErrResetNeeded) },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "backend unavailable",
+			abort:      func(c *gin.Context) { abortInvalidToken(c, testRealm, client.ErrUnavailable) },
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/token/verify", nil)
+
+			tt.abort(c)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("статус %d, ожидался %d", recorder.Code, tt.wantStatus)
+			}
+			if got := recorder.Header().Get("WWW-Authenticate"); got != tt.wantHeader {
+				t.Errorf("WWW-Authenticate %q, ожидалось %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Файл: middleware/csrf.go
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"auth-service/handlers"
+	"auth-service/logger"
+	"auth-service/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRF защищает от подделки запросов эндпоинты, аутентифицированные по cookie (double-submit):
+// значение CSRF cookie должно совпадать с заголовком csrf_header. Сайт злоумышленника
+// может заставить браузер отправить cookie, но не может прочитать ее и повторить в заголовке.
+// Запросы с токеном в заголовке Authorization не проверяются. Ставится после AuthMiddleware.
+func CSRF(appCtx *handlers.AppContext) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !handlers.IsCookieCredential(c) {
+			c.Next()
+			return
+		}
+
+		cfg := appCtx.Config().SessionCookies
+		cookieToken, err := c.Cookie(cfg.CSRFName)
+		headerToken := c.GetHeader(cfg.CSRFHeader)
+		if err != nil || cookieToken == "" ||
+			subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) != 1 {
+			appCtx.RequestLogger(c).Component(logger.ComponentMiddleware).
+				Warn("Отклонен запрос с cookie сессии без корректного CSRF токена")
+			problem.Abort(c, problem.CodeInvalidCSRFToken)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+// Файл: middleware/metrics.go
+package middleware
+
+import (
+	"time"
+
+	"auth-service/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute подставляется вместо маршрута для запросов, не совпавших ни с одним маршрутом,
+// чтобы произвольные пути не порождали новые ряды метрик
+const unmatchedRoute = "unmatched"
+
+// Metrics учитывает длительность обработки запросов по шаблону маршрута
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,207 @@
+// Файл: middleware/ratelimit.go
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/logger"
+	"auth-service/metrics"
+	"auth-service/problem"
+	"auth-service/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPeekBody ограничивает часть тела запроса, в которой ищется имя пользователя
+const maxPeekBody = 64 << 10
+
+// RateLimit ограничивает частоту запросов по правилам rate_limit.rules. Правила
+// читаются из текущей конфигурации, поэтому их изменение применяется без перезапуска.
+// Ответ получает заголовки X-RateLimit-* по самому строгому из сработавших правил,
+// а при превышении лимита - код 429 и Retry-After.
+func RateLimit(appCtx *handlers.AppContext, store ratelimit.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := appCtx.Config()
+		route := c.FullPath()
+		if !cfg.RateLimit.Enabled || route == "" {
+			c.Next()
+			return
+		}
+
+		log := appCtx.RequestLogger(c).Component(logger.ComponentMiddleware)
+		subject := &rateLimitSubject{c: c, appCtx: appCtx, cfg: cfg}
+
+		var tightest *ratelimit.Result
+		for _, rule := range cfg.RateLimit.Rules {
+			if !matchRoute(rule.Routes, route) {
+				continue
+			}
+			value, ok := subject.key(rule.Key)
+			if !ok {
+				continue
+			}
+
+			rate := float64(rule.Limit) / rule.Period.Std().Seconds()
+			result, err := store.Take(c.Request.Context(), rule.Name+":"+rule.Key+":"+value, rate, rule.Burst)
+			if err != nil {
+				metrics.ObserveRateLimitError()
+				log.Error("Ошибка хранилища ограничения частоты (правило %s): %v", rule.Name, err)
+				if cfg.RateLimit.FailClosed {
+					problem.Abort(c, problem.CodeRateLimitUnavailable)
+					return
+				}
+				continue
+			}
+
+			if !result.Allowed {
+				setRateLimitHeaders(c, result)
+				c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				metrics.ObserveRateLimited(rule.Name)
+				log.Warn("Превышен лимит запросов по правилу %s (%s)", rule.Name, rule.Key)
+				problem.Abort(c, problem.CodeRateLimited)
+				return
+			}
+			if tightest == nil || result.Remaining < tightest.Remaining {
+				tightest = &result
+			}
+		}
+
+		if tightest != nil {
+			setRateLimitHeaders(c, *tightest)
+		}
+		c.Next()
+	}
+}
+
+// matchRoute проверяет, относится ли правило к маршруту
+func matchRoute(routes []string, route string) bool {
+	for _, r := range routes {
+		if r == "*" || r == route {
+			return true
+		}
+	}
+	return false
+}
+
+// setRateLimitHeaders сообщает клиенту емкость, остаток и время до заполнения корзины
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд, но не меньше одной
+func ceilSeconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
+
+// rateLimitSubject определяет значения ключей правил для запроса. Имя пользователя
+// и агентство берутся из токена с проверенной подписью или, для входа, из тела запроса.
+type rateLimitSubject struct {
+	c      *gin.Context
+	appCtx *handlers.AppContext
+	cfg    *config.Config
+
+	claims       *handlers.Claims
+	claimsParsed bool
+}
+
+// key возвращает значение ключа правила; false, если в запросе его нет
+func (s *rateLimitSubject) key(kind string) (string, bool) {
+	switch kind {
+	case config.RateLimitKeyIP:
+		return s.c.ClientIP(), true
+	case config.RateLimitKeyGlobal:
+		return "all", true
+	case config.RateLimitKeyAPIKey:
+		apiKey := s.c.GetHeader(s.cfg.RateLimit.APIKeyHeader)
+		if apiKey == "" {
+			return "", false
+		}
+		// Ключ API не должен попадать в хранилище в открытом виде
+		sum := sha256.Sum256([]byte(apiKey))
+		return hex.EncodeToString(sum[:16]), true
+	case config.RateLimitKeyAgency:
+		if claims := s.verifiedClaims(); claims != nil {
+			return strconv.Itoa(claims.AgencyID), true
+		}
+		return "", false
+	case config.RateLimitKeyUsername:
+		username := ""
+		if claims := s.verifiedClaims(); claims != nil {
+			username = claims.Username
+		} else {
+			username = s.bodyUsername()
+		}
+		// Регистр не учитывается, чтобы лимит нельзя было обойти вариантами написания имени
+		username = strings.ToLower(strings.TrimSpace(username))
+		return username, username != ""
+	}
+	return "", false
+}
+
+// verifiedClaims разбирает токен запроса с проверкой подписи и срока действия,
+// но без обращения к БД: ключ правила не должен зависеть от данных, подделанных клиентом
+func (s *rateLimitSubject) verifiedClaims() *handlers.Claims {
+	if s.claimsParsed {
+		return s.claims
+	}
+	s.claimsParsed = true
+
+	credential, err := ExtractCredential(s.c.Request, s.cfg)
+	if err != nil {
+		return nil
+	}
+	claims := &handlers.Claims{}
+	if token, err := s.appCtx.Keys.Parse(credential.Token, claims); err == nil && token.Valid {
+		s.claims = claims
+	}
+	return s.claims
+}
+
+// bodyUsername читает имя пользователя из JSON или формы в теле запроса и
+// возвращает тело на место, чтобы его мог разобрать обработчик
+func (s *rateLimitSubject) bodyUsername() string {
+	req := s.c.Request
+	// Форму уже мог разобрать поиск токена в теле (источник body): тело прочитано
+	if req.PostForm != nil {
+		return req.PostForm.Get("username")
+	}
+	if req.Body == nil {
+		return ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxPeekBody))
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+	if err != nil {
+		return ""
+	}
+
+	// Вход принимает JSON независимо от Content-Type, поэтому формат определяется по содержимому
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var body struct {
+			Username string `json:"username"`
+		}
+		json.Unmarshal(trimmed, &body)
+		return body.Username
+	}
+	values, _ := url.ParseQuery(string(data))
+	return values.Get("username")
+}
+
+// readCloser читает из восстановленного тела и закрывает исходное
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
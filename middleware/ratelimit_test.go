@@ -0,0 +1,256 @@
+// Файл: middleware/ratelimit_test.go
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/logger"
+	"auth-service/problem"
+	"auth-service/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failingStore - хранилище, которое всегда возвращает ошибку
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, float64, int) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("хранилище недоступно")
+}
+
+// newRateLimitRouter создает маршрутизатор с ограничением частоты на /login и /verify.
+// Обработчик /login возвращает имя пользователя из тела, чтобы проверить, что тело не потеряно.
+func newRateLimitRouter(t *testing.T, rl config.RateLimitConfig, store ratelimit.Store) *gin.Engine {
+	t.Helper()
+	return newRateLimitRouterConfig(t, &config.Config{RateLimit: rl}, store)
+}
+
+// newRateLimitRouterConfig создает такой же маршрутизатор с произвольной конфигурацией
+func newRateLimitRouterConfig(t *testing.T, cfg *config.Config, store ratelimit.Store) *gin.Engine {
+	t.Helper()
+
+	cfg.LogLevel = "error"
+	log, err := logger.NewColorfulLogger(cfg)
+	if err != nil {
+		t.Fatalf("NewColorfulLogger: %v", err)
+	}
+	appCtx := &handlers.AppContext{Logger: log.WithWriter(io.Discard)}
+	appCtx.SetConfig(cfg)
+
+	router := gin.New()
+	limit := RateLimit(appCtx, store)
+	router.POST("/login", limit, func(c *gin.Context) {
+		var body struct {
+			Username string `json:"username" form:"username"`
+		}
+		c.ShouldBind(&body)
+		c.String(http.StatusOK, body.Username)
+	})
+	router.POST("/verify", limit, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// rateLimitRequest отправляет запрос от клиента с адресом ip
+func rateLimitRequest(router *gin.Engine, path, ip, username string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"username":"`+username+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":40000"
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// rule создает правило с емкостью, равной limit
+func rule(name, key string, limit int, routes ...string) config.RateLimitRule {
+	return config.RateLimitRule{Name: name, Routes: routes, Key: key, Limit: limit, Period: config.Duration(time.Minute), Burst: limit}
+}
+
+func TestRateLimitRejectsWithRetryAfter(t *testing.T) {
+	router := newRateLimitRouter(t, config.RateLimitConfig{
+		Enabled: true,
+		Rules:   []config.RateLimitRule{rule("login_ip", config.RateLimitKeyIP, 2, "/login")},
+	}, ratelimit.NewMemoryStore())
+
+	for i, remaining := range []string{"1", "0"} {
+		recorder := rateLimitRequest(router, "/login", "10.0.0.1", "user123")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("запрос %d: статус %d, ожидался 200", i+1, recorder.Code)
+		}
+		if got := recorder.Body.String(); got != "user123" {
+			t.Fatalf("обработчик получил имя %q, тело запроса потеряно", got)
+		}
+		checkHeader(t, recorder, "X-RateLimit-Limit", "2")
+		checkHeader(t, recorder, "X-RateLimit-Remaining", remaining)
+		if recorder.Header().Get("Retry-After") != "" {
+			t.Errorf("Retry-After у разрешенного запроса")
+		}
+	}
+	// Два запроса из двух возможных за минуту: корзина заполнится через минуту
+	recorder := rateLimitRequest(router, "/login", "10.0.0.1", "user123")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("статус %d, ожидался 429", recorder.Code)
+	}
+	checkHeader(t, recorder, "Retry-After", "30")
+	checkHeader(t, recorder, "X-RateLimit-Limit", "2")
+	checkHeader(t, recorder, "X-RateLimit-Remaining", "0")
+	checkHeader(t, recorder, "X-RateLimit-Reset", "60")
+	checkProblemCode(t, recorder, problem.CodeRateLimited)
+
+	// Правило не относится к другим маршрутам
+	recorder = rateLimitRequest(router, "/verify", "10.0.0.1", "user123")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("X-RateLimit-Limit") != "" {
+		t.Fatalf("/verify: статус %d, заголовки %v; правило /login не должно применяться", recorder.Code, recorder.Header())
+	}
+}
+
+func TestRateLimitHeadersUseTightestRule(t *testing.T) {
+	router := newRateLimitRouter(t, config.RateLimitConfig{
+		Enabled: true,
+		Rules: []config.RateLimitRule{
+			rule("all_ip", config.RateLimitKeyIP, 10, "*"),
+			rule("login_global", config.RateLimitKeyGlobal, 3, "/login"),
+		},
+	}, ratelimit.NewMemoryStore())
+
+	recorder := rateLimitRequest(router, "/login", "10.0.0.1", "user123")
+	checkHeader(t, recorder, "X-RateLimit-Limit", "3")
+	checkHeader(t, recorder, "X-RateLimit-Remaining", "2")
+	checkHeader(t, recorder, "X-RateLimit-Reset", "20")
+
+	recorder = rateLimitRequest(router, "/verify", "10.0.0.1", "user123")
+	checkHeader(t, recorder, "X-RateLimit-Limit", "10")
+	checkHeader(t, recorder, "X-RateLimit-Remaining", "8")
+}
+
+func TestRateLimitPerClientAndGlobal(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   config.RateLimitRule
+		second string // Клиент второго запроса: адрес и имя пользователя через пробел
+		want   int
+	}{
+		{"ip: same client", rule("r", config.RateLimitKeyIP, 1, "/login"), "10.0.0.1 alice", http.StatusTooManyRequests},
+		{"ip: other client", rule("r", config.RateLimitKeyIP, 1, "/login"), "10.0.0.2 alice", http.StatusOK},
+		{"username: same user", rule("r", config.RateLimitKeyUsername, 1, "/login"), "10.0.0.2 alice", http.StatusTooManyRequests},
+		{"username: case-insensitive", rule("r", config.RateLimitKeyUsername, 1, "/login"), "10.0.0.2 ALICE", http.StatusTooManyRequests},
+		{"username: other user", rule("r", config.RateLimitKeyUsername, 1, "/login"), "10.0.0.1 bob", http.StatusOK},
+		{"global: other client", rule("r", config.RateLimitKeyGlobal, 1, "/login"), "10.0.0.2 bob", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRateLimitRouter(t, config.RateLimitConfig{
+				Enabled: true,
+				Rules:   []config.RateLimitRule{tt.rule},
+			}, ratelimit.NewMemoryStore())
+
+			if recorder := rateLimitRequest(router, "/login", "10.0.0.1", "alice"); recorder.Code != http.StatusOK {
+				t.Fatalf("первый запрос: статус %d, ожидался 200", recorder.Code)
+			}
+			ip, username, _ := strings.Cut(tt.second, " ")
+			if recorder := rateLimitRequest(router, "/login", ip, username); recorder.Code != tt.want {
+				t.Fatalf("второй запрос: статус %d, ожидался %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitStoreErrors(t *testing.T) {
+	rules := []config.RateLimitRule{rule("login_ip", config.RateLimitKeyIP, 1, "/login")}
+
+	t.Run("fail open", func(t *testing.T) {
+		router := newRateLimitRouter(t, config.RateLimitConfig{Enabled: true, Rules: rules}, failingStore{})
+		for i := 0; i < 3; i++ {
+			recorder := rateLimitRequest(router, "/login", "10.0.0.1", "user123")
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("запрос %d: статус %d, ожидался 200 при недоступном хранилище", i+1, recorder.Code)
+			}
+			if recorder.Header().Get("X-RateLimit-Limit") != "" {
+				t.Errorf("заголовки X-RateLimit-* без ответа хранилища")
+			}
+		}
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		router := newRateLimitRouter(t, config.RateLimitConfig{Enabled: true, FailClosed: true, Rules: rules}, failingStore{})
+		recorder := rateLimitRequest(router, "/login", "10.0.0.1", "user123")
+		if recorder.Code != http.StatusServiceUnavailable {
+			t.Fatalf("статус %d, ожидался 503", recorder.Code)
+		}
+		checkProblemCode(t, recorder, problem.CodeRateLimitUnavailable)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := newRateLimitRouter(t, config.RateLimitConfig{Rules: rules}, failingStore{})
+		if recorder := rateLimitRequest(router, "/login", "10.0.0.1", "user123"); recorder.Code != http.StatusOK {
+			t.Fatalf("статус %d при выключенном ограничении", recorder.Code)
+		}
+	})
+}
+
+func checkHeader(t *testing.T, recorder *httptest.ResponseRecorder, name, want string) {
+	t.Helper()
+	if got := recorder.Header().Get(name); got != want {
+		t.Errorf("%s = %q, ожидалось %q", name, got, want)
+	}
+}
+
+func checkProblemCode(t *testing.T, recorder *httptest.ResponseRecorder, want problem.Code) {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Code != string(want) {
+		t.Errorf("код ошибки %q (%v), ожидался %s", body.Code, err, want)
+	}
+}
+
+func TestRateLimitUsernameFromParsedForm(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{
+			Enabled: true,
+			Rules:   []config.RateLimitRule{rule("login_username", config.RateLimitKeyUsername, 1, "/login")},
+		},
+	}
+	// Поиск токена в теле разбирает форму до того, как правило читает имя пользователя
+	cfg.Credentials = config.CredentialsConfig{
+		Sources:    []string{config.CredentialSourceHeader, config.CredentialSourceBody},
+		QueryParam: "access_token",
+		Realm:      testRealm,
+	}
+	router := newRateLimitRouterConfig(t, cfg, ratelimit.NewMemoryStore())
+
+	formLogin := func(ip, username string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {username}, "password": {"pass123!!"}}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.RemoteAddr = ip + ":40000"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := formLogin("10.0.0.1", "alice")
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "alice" {
+		t.Fatalf("первый вход: статус %d, тело %q", recorder.Code, recorder.Body.String())
+	}
+	checkHeader(t, recorder, "X-RateLimit-Limit", "1")
+
+	if recorder := formLogin("10.0.0.2", "Alice"); recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("повторный вход того же пользователя: статус %d, ожидался 429", recorder.Code)
+	}
+	if recorder := formLogin("10.0.0.2", "bob"); recorder.Code != http.StatusOK {
+		t.Fatalf("вход другого пользователя: статус %d, ожидался 200", recorder.Code)
+	}
+}
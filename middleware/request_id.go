@@ -0,0 +1,59 @@
+// Файл: middleware/request_id.go
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"auth-service/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader задает заголовок, через который передается идентификатор запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину принимаемого от клиента идентификатора
+const maxRequestIDLength = 128
+
+// RequestID принимает идентификатор запроса из заголовка X-Request-ID
+// или генерирует новый и сохраняет его в контексте запроса
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = generateRequestID()
+		}
+
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
+
+// isValidRequestID проверяет, что идентификатор безопасно записывать в логи и заголовки
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// generateRequestID создает случайный идентификатор запроса
+func generateRequestID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes)
+}
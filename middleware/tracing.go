@@ -0,0 +1,47 @@
+// Файл: middleware/tracing.go
+package middleware
+
+import (
+	"net/http"
+
+	"auth-service/logger"
+	"auth-service/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing начинает серверный спан для каждого запроса, продолжая трассу
+// из заголовка traceparent, если клиент его передал
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.StartWithKind(ctx, c.Request.Method+" "+route, trace.SpanKindServer,
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.ClientAddress(c.ClientIP()),
+			attribute.String("request_id", logger.RequestIDFromContext(ctx)),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if username := c.GetString("username"); username != "" {
+			span.SetAttributes(attribute.String("enduser.id", username))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -1,6 +1,8 @@
 // Файл: models/models.go
 package models
 
+import "time"
+
 // User представляет данные пользователя
 // @Description Данные пользователя для аутентификации
 type User struct {
@@ -11,8 +13,8 @@ type User struct {
 // TokenResponse представляет ответ с токеном доступа
 // @Description Ответ с токеном доступа
 type TokenResponse struct {
-	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // JWT токен доступа
-	TokenType   string `json:"token_type" example:"bearer"`                                    // Тип токена (обычно "bearer")
+	AccessToken string `json:"access_token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."` // JWT токен доступа; не передается при обновлении сессии по cookie
+	TokenType   string `json:"token_type" example:"bearer"`                                              // Тип токена: "bearer" или "cookie"
 }
 
 // TokenVerify представляет запрос на проверку токена
@@ -37,10 +39,12 @@ type Message struct {
 
 // UserData представляет данные пользователя из БД
 type UserData struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
-	AgencyID int    `json:"agency_id"`
-	JWTToken string `json:"jwt_token"`
+	Login                 string `json:"login"`
+	Password              string `json:"password"`
+	AgencyID              int    `json:"agency_id"`
+	JWTToken              string `json:"jwt_token"`
+	Disabled              bool   `json:"disabled"`                // Учетная запись отключена администратором
+	PasswordResetRequired bool   `json:"password_reset_required"` // Вход запрещен до смены пароля
 }
 
 // LocalAPIRequest представляет запрос к локальному API
@@ -54,7 +58,119 @@ type LocalAPIRequest struct {
 	} `json:"token_data"`
 }
 
-// ErrorResponse представляет структуру ответа с ошибкой
+// UpdatePasswordRequest представляет запрос к локальному API на замену хеша пароля
+type UpdatePasswordRequest struct {
+	MicroName struct {
+		Name string `json:"name"`
+	} `json:"micro_name"`
+	PasswordData struct {
+		Login    string `json:"login"`
+		Password string `json:"password"` // Хеш пароля, а не пароль в открытом виде
+	} `json:"password_data"`
+}
+
+// ListUsersRequest представляет запрос к локальному API на выборку пользователей
+type ListUsersRequest struct {
+	MicroName struct {
+		Name string `json:"name"`
+	} `json:"micro_name"`
+	Filter struct {
+		AgencyID *int   `json:"agency_id,omitempty"`
+		Search   string `json:"search,omitempty"` // Подстрока логина
+		Offset   int    `json:"offset"`
+		Limit    int    `json:"limit"`
+	} `json:"filter"`
+}
+
+// UserStatus задает изменяемые администратором признаки учетной записи; nil - не менять
+type UserStatus struct {
+	Disabled              *bool `json:"disabled,omitempty"`
+	PasswordResetRequired *bool `json:"password_reset_required,omitempty"`
+}
+
+// UpdateUserRequest представляет запрос к локальному API на изменение признаков учетной записи
+type UpdateUserRequest struct {
+	MicroName struct {
+		Name string `json:"name"`
+	} `json:"micro_name"`
+	UserData struct {
+		Login string `json:"login"`
+		UserStatus
+	} `json:"user_data"`
+}
+
+// DeleteUserRequest представляет запрос к локальному API на удаление пользователя
+type DeleteUserRequest struct {
+	MicroName struct {
+		Name string `json:"name"`
+	} `json:"micro_name"`
+	UserData struct {
+		Login string `json:"login"`
+	} `json:"user_data"`
+}
+
+// AdminUser представляет учетную запись в ответах административного API
+// @Description Учетная запись пользователя без хеша пароля и токена
+type AdminUser struct {
+	Username              string `json:"username" example:"user123"`              // Логин пользователя
+	AgencyID              int    `json:"agency_id" example:"42"`                  // ID агентства
+	Disabled              bool   `json:"disabled" example:"false"`                // Учетная запись отключена
+	PasswordResetRequired bool   `json:"password_reset_required" example:"false"` // Требуется смена пароля
+	HasSession            bool   `json:"has_session" example:"true"`              // В БД сохранен токен доступа
+}
+
+// AdminUserList представляет страницу списка пользователей
+// @Description Страница списка пользователей
+type AdminUserList struct {
+	Users  []AdminUser `json:"users"`               // Пользователи страницы
+	Total  int         `json:"total" example:"120"` // Всего пользователей по фильтру
+	Offset int         `json:"offset" example:"0"`  // Смещение страницы
+	Limit  int         `json:"limit" example:"50"`  // Размер страницы
+}
+
+// SessionInfo описывает действующий токен доступа пользователя
+// @Description Действующая сессия пользователя
+type SessionInfo struct {
+	TokenID   string    `json:"token_id" example:"4f1c9a0e2b7d4c3a8e6f5d2c1b0a9f8e"` // Идентификатор токена (jti)
+	IssuedAt  time.Time `json:"issued_at"`                                           // Время выпуска
+	ExpiresAt time.Time `json:"expires_at"`                                          // Время истечения
+}
+
+// AdminUserDetails представляет сведения о пользователе для службы поддержки
+// @Description Учетная запись, сессии и состояние входа пользователя
+type AdminUserDetails struct {
+	AdminUser
+	Sessions     []SessionInfo `json:"sessions"`                                   // Действующие сессии; сервис хранит один токен доступа на пользователя
+	LastLogin    *time.Time    `json:"last_login,omitempty"`                       // Последний успешный вход по журналу аудита
+	LastLoginIP  string        `json:"last_login_ip,omitempty" example:"10.0.0.1"` // IP последнего успешного входа
+	FailedLogins int           `json:"failed_logins" example:"0"`                  // Неудачные попытки входа в текущем окне блокировки
+	LockedUntil  *time.Time    `json:"locked_until,omitempty"`                     // Вход заблокирован до этого времени
+}
+
+// ErrorResponse представляет ответ с ошибкой в формате RFC 7807 (application/problem+json)
+// @Description Описание ошибки: стабильный код и сообщение на языке из Accept-Language (ru, en)
 type ErrorResponse struct {
-	Error string `json:"error" example:"Описание ошибки"`
+	Type      string `json:"type" example:"urn:auth-service:error:invalid_credentials"` // URI типа ошибки
+	Title     string `json:"title" example:"Неверное имя пользователя или пароль"`      // Сообщение об ошибке
+	Status    int    `json:"status" example:"401"`                                      // Код HTTP ответа
+	Detail    string `json:"detail,omitempty"`                                          // Пояснение к конкретному случаю
+	Instance  string `json:"instance,omitempty" example:"/login"`                       // Путь запроса
+	Code      string `json:"code" example:"invalid_credentials"`                        // Машиночитаемый код ошибки
+	RequestID string `json:"request_id,omitempty"`                                      // Идентификатор запроса для поиска в логах
+	Error     string `json:"error" example:"Неверное имя пользователя или пароль"`      // Совпадает с title; сохранено для совместимости
+}
+
+// LogLevelRequest представляет запрос на изменение уровня логирования
+// @Description Запрос на временное изменение уровня логирования
+type LogLevelRequest struct {
+	Level     string `json:"level" binding:"required,oneof=debug info warn error" example:"debug"` // Новый уровень логирования
+	Component string `json:"component" example:"handlers"`                                         // Компонент (handlers, middleware, client, access); пусто - общий уровень
+	Duration  string `json:"duration" example:"15m"`                                               // Через сколько вернуть прежний уровень; пусто - значение из конфигурации, "0" - не возвращать
+}
+
+// LogLevelResponse представляет текущие уровни логирования
+// @Description Текущие уровни логирования
+type LogLevelResponse struct {
+	Level      string            `json:"level" example:"info"` // Общий уровень логирования
+	Components map[string]string `json:"components,omitempty"` // Переопределения уровней по компонентам
 }
@@ -0,0 +1,88 @@
+// Файл: password/argon2.go
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+
+	// Ограничения параметров хешей из БД: поврежденная запись не должна
+	// заставить сервис выделить гигабайты памяти на одну проверку
+	argon2MaxMemoryKiB = 1 << 20
+	argon2MaxTime      = 64
+	argon2MinKeyLen    = 16
+	argon2MaxKeyLen    = 64
+)
+
+// argon2Scheme - хеширование argon2id в формате PHC:
+// $argon2id$v=19$m=<КиБ>,t=<проходы>,p=<потоки>$<соль>$<хеш>
+type argon2Scheme struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	keyLen  uint32
+}
+
+func (s argon2Scheme) hash(password []byte) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("ошибка генерации соли: %w", err)
+	}
+	key := argon2.IDKey(password, salt, s.time, s.memory, s.threads, s.keyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		s.memory, s.time, s.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (s argon2Scheme) key() string {
+	return fmt.Sprintf("argon2id:m=%d,t=%d,p=%d,l=%d", s.memory, s.time, s.threads, s.keyLen)
+}
+
+// decodeArgon2 разбирает хеш argon2id в формате PHC
+func decodeArgon2(encoded string) (scheme, func([]byte) bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, fmt.Errorf("%w: неподдерживаемая версия argon2id %q", ErrUnknownHash, parts[2])
+	}
+
+	var s argon2Scheme
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &s.memory, &s.time, &s.threads); err != nil {
+		return nil, nil, fmt.Errorf("%w: некорректные параметры argon2id: %w", ErrUnknownHash, err)
+	}
+	if s.time < 1 || s.time > argon2MaxTime || s.threads < 1 ||
+		s.memory < 8*uint32(s.threads) || s.memory > argon2MaxMemoryKiB {
+		return nil, nil, fmt.Errorf("%w: параметры argon2id вне допустимых границ", ErrUnknownHash)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) < 8 {
+		return nil, nil, fmt.Errorf("%w: некорректная соль argon2id", ErrUnknownHash)
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) < argon2MinKeyLen || len(want) > argon2MaxKeyLen {
+		return nil, nil, fmt.Errorf("%w: некорректный хеш argon2id", ErrUnknownHash)
+	}
+	s.keyLen = uint32(len(want))
+
+	verify := func(password []byte) bool {
+		got := argon2.IDKey(password, salt, s.time, s.memory, s.threads, s.keyLen)
+		return subtle.ConstantTimeCompare(got, want) == 1
+	}
+	return s, verify, nil
+}
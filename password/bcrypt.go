@@ -0,0 +1,43 @@
+// Файл: password/bcrypt.go
+package password
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptScheme - хеширование bcrypt с заданной стоимостью
+type bcryptScheme struct {
+	cost int
+}
+
+func (s bcryptScheme) hash(password []byte) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(password, s.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (s bcryptScheme) key() string {
+	return "bcrypt:" + strconv.Itoa(s.cost)
+}
+
+// decodeBcrypt извлекает стоимость из хеша bcrypt. Хеши со стоимостью больше maxCost
+// отклоняются: каждая проверка такого хеша, в том числе фиктивная для несуществующих
+// пользователей, заняла бы минуты.
+func decodeBcrypt(encoded string, maxCost int) (scheme, func([]byte) bool, error) {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return nil, nil, err
+	}
+	if cost > maxCost {
+		return nil, nil, fmt.Errorf("%w: стоимость bcrypt %d больше допустимой %d", ErrUnknownHash, cost, maxCost)
+	}
+	verify := func(password []byte) bool {
+		return bcrypt.CompareHashAndPassword([]byte(encoded), password) == nil
+	}
+	return bcryptScheme{cost: cost}, verify, nil
+}
@@ -0,0 +1,146 @@
+// Файл: password/password.go
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"auth-service/config"
+)
+
+// ErrUnknownHash возвращается, если формат хеша не распознан
+var ErrUnknownHash = errors.New("неизвестный формат хеша пароля")
+
+// dummyPassword хешируется для сравнения паролей несуществующих пользователей
+const dummyPassword = "dummy-password-for-constant-time-login"
+
+// scheme описывает параметры одного алгоритма хеширования
+type scheme interface {
+	// hash вычисляет хеш пароля с этими параметрами
+	hash(password []byte) (string, error)
+	// key однозначно описывает алгоритм и параметры, по нему кешируются фиктивные хеши
+	key() string
+}
+
+// Hasher хеширует пароли выбранным в конфигурации алгоритмом и проверяет хеши
+// bcrypt и argon2id, определяя алгоритм по формату хеша
+type Hasher struct {
+	current scheme
+	pepper  []byte
+	// maxBcryptCost ограничивает стоимость проверяемых хешей bcrypt
+	maxBcryptCost int
+
+	// last - параметры последнего проверенного хеша из БД
+	last atomic.Pointer[scheme]
+	// dummies хранит хеши dummyPassword по параметрам схемы
+	dummies sync.Map
+}
+
+// New создает Hasher по секции password_hashing конфигурации
+func New(cfg config.PasswordHashingConfig) *Hasher {
+	var current scheme = bcryptScheme{cost: cfg.BcryptCost}
+	if cfg.Algorithm == config.PasswordArgon2id {
+		current = argon2Scheme{
+			memory:  uint32(cfg.Argon2MemoryKiB),
+			time:    uint32(cfg.Argon2Iterations),
+			threads: uint8(cfg.Argon2Parallelism),
+			keyLen:  argon2KeyLen,
+		}
+	}
+
+	// Без явного ограничения проверяются только хеши не дороже bcrypt_cost
+	h := &Hasher{current: current, maxBcryptCost: max(cfg.BcryptMaxCost, cfg.BcryptCost)}
+	if cfg.Pepper != "" {
+		h.pepper = []byte(cfg.Pepper)
+	}
+	return h
+}
+
+// Hash вычисляет хеш пароля текущим алгоритмом с перцем, если он задан
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.hash(h.peppered(password))
+}
+
+// Verify сравнивает пароль с хешем из БД. needsRehash сообщает, что пароль верен,
+// но хеш вычислен другим алгоритмом, с другими параметрами или без перца
+// и его следует заменить результатом Hash. unpeppered сообщает, что пароль подошел
+// только без перца: такой хеш создан до включения перца и должен быть заменен.
+func (h *Hasher) Verify(password, encoded string) (ok, needsRehash, unpeppered bool) {
+	s, verify, err := h.decode(encoded)
+	if err != nil {
+		return false, false, false
+	}
+	h.last.Store(&s)
+
+	if h.pepper != nil {
+		if verify(h.peppered(password)) {
+			return true, s.key() != h.current.key(), false
+		}
+		// Хеши, созданные до включения перца, проверяются без него и подлежат замене
+		if verify([]byte(password)) {
+			return true, true, true
+		}
+		return false, false, false
+	}
+
+	if !verify([]byte(password)) {
+		return false, false, false
+	}
+	return true, s.key() != h.current.key(), false
+}
+
+// VerifyDummy выполняет такую же по длительности проверку, как Verify с неверным паролем,
+// для пользователя, которого нет в БД, чтобы по времени ответа нельзя было узнать,
+// существует ли учетная запись. Параметры совпадают с последним проверенным хешем.
+func (h *Hasher) VerifyDummy(password string) {
+	s := h.current
+	if last := h.last.Load(); last != nil {
+		s = *last
+	}
+
+	encoded, ok := h.dummies.Load(s.key())
+	if !ok {
+		generated, err := s.hash([]byte(dummyPassword))
+		if err != nil {
+			return
+		}
+		encoded, _ = h.dummies.LoadOrStore(s.key(), generated)
+	}
+
+	_, verify, err := h.decode(encoded.(string))
+	if err != nil {
+		return
+	}
+	verify(h.peppered(password))
+	if h.pepper != nil {
+		verify([]byte(password))
+	}
+}
+
+// peppered подмешивает серверный перец: HMAC-SHA256 пароля в base64 укладывается
+// в ограничение bcrypt на 72 байта независимо от длины пароля
+func (h *Hasher) peppered(password string) []byte {
+	if h.pepper == nil {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// decode определяет алгоритм по префиксу хеша и возвращает его параметры и функцию проверки
+func (h *Hasher) decode(encoded string) (scheme, func(password []byte) bool, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return decodeArgon2(encoded)
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return decodeBcrypt(encoded, h.maxBcryptCost)
+	default:
+		return nil, nil, ErrUnknownHash
+	}
+}
@@ -0,0 +1,72 @@
+// Файл: password/password_test.go
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"auth-service/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifyBcryptCostLimit(t *testing.T) {
+	encoded, err := bcrypt.GenerateFromPassword([]byte("pass123!!"), 6)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.PasswordHashingConfig
+		wantOK  bool
+		wantErr bool
+	}{
+		{"within limit", config.PasswordHashingConfig{BcryptCost: 4, BcryptMaxCost: 6}, true, false},
+		{"above limit", config.PasswordHashingConfig{BcryptCost: 4, BcryptMaxCost: 5}, false, true},
+		{"no limit uses bcrypt_cost", config.PasswordHashingConfig{BcryptCost: 4}, false, true},
+		{"bcrypt_cost above limit", config.PasswordHashingConfig{BcryptCost: 6, BcryptMaxCost: 5}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(tt.cfg)
+			if _, _, err := h.decode(string(encoded)); (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrUnknownHash)) {
+				t.Fatalf("decode: %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+			if ok, _, _ := h.Verify("pass123!!", string(encoded)); ok != tt.wantOK {
+				t.Fatalf("Verify = %v, ожидалось %v", ok, tt.wantOK)
+			}
+			// Отклоненный хеш не задает параметры фиктивной проверки
+			if tt.wantErr && h.last.Load() != nil {
+				t.Fatal("параметры отклоненного хеша сохранены для VerifyDummy")
+			}
+		})
+	}
+}
+
+func TestVerifyUnpepperedFallback(t *testing.T) {
+	plain := New(config.PasswordHashingConfig{BcryptCost: 4})
+	encoded, err := plain.Hash("pass123!!")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	peppered := New(config.PasswordHashingConfig{BcryptCost: 4, Pepper: "pepper"})
+
+	if ok, needsRehash, unpeppered := peppered.Verify("pass123!!", encoded); !ok || !needsRehash || !unpeppered {
+		t.Fatalf("хеш без перца: (%v, %v, %v), ожидалось (true, true, true)", ok, needsRehash, unpeppered)
+	}
+	if ok, _, unpeppered := peppered.Verify("wrong", encoded); ok || unpeppered {
+		t.Fatalf("неверный пароль: (%v, %v), ожидалось (false, false)", ok, unpeppered)
+	}
+
+	rehashed, err := peppered.Hash("pass123!!")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if ok, needsRehash, unpeppered := peppered.Verify("pass123!!", rehashed); !ok || needsRehash || unpeppered {
+		t.Fatalf("хеш с перцем: (%v, %v, %v), ожидалось (true, false, false)", ok, needsRehash, unpeppered)
+	}
+	if ok, _, _ := plain.Verify("pass123!!", rehashed); ok {
+		t.Fatal("хеш с перцем принят без перца")
+	}
+}
@@ -0,0 +1,141 @@
+// Файл: problem/codes.go
+package problem
+
+import "net/http"
+
+// Code - стабильный машиночитаемый код ошибки, на который могут опираться клиенты
+type Code string
+
+// Коды ошибок API
+const (
+	CodeInvalidRequest          Code = "invalid_request"
+	CodeInvalidParameter        Code = "invalid_parameter"
+	CodeUnknownComponent        Code = "unknown_component"
+	CodeMissingCredentials      Code = "missing_credentials"
+	CodeMalformedCredentials    Code = "malformed_credentials"
+	CodeInvalidCredentials      Code = "invalid_credentials"
+	CodeInvalidToken            Code = "invalid_token"
+	CodeTokenExpired            Code = "token_expired"
+	CodeTokenRevoked            Code = "token_revoked"
+	CodeInvalidCSRFToken        Code = "invalid_csrf_token"
+	CodeClientCertRequired      Code = "client_certificate_required"
+	CodeInvalidAdminToken       Code = "invalid_admin_token"
+	CodeAdminNotConfigured      Code = "admin_access_not_configured"
+	CodeAccountDisabled         Code = "account_disabled"
+	CodePasswordResetRequired   Code = "password_reset_required"
+	CodeNotFound                Code = "not_found"
+	CodeUserNotFound            Code = "user_not_found"
+	CodeRateLimited             Code = "rate_limited"
+	CodeLoginLocked             Code = "too_many_login_attempts"
+	CodeBackendUnavailable      Code = "backend_unavailable"
+	CodeRateLimitUnavailable    Code = "rate_limit_unavailable"
+	CodeSessionStoreUnavailable Code = "session_store_unavailable"
+	CodeInternal                Code = "internal_error"
+)
+
+// Языки сообщений
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// definition задает код ответа и шаблоны сообщения ошибки на поддерживаемых языках
+type definition struct {
+	status   int
+	messages map[string]string
+}
+
+var catalog = map[Code]definition{
+	CodeInvalidRequest: {http.StatusBadRequest, map[string]string{
+		LangRU: "Некорректные данные запроса",
+		LangEN: "Invalid request data",
+	}},
+	CodeInvalidParameter: {http.StatusBadRequest, map[string]string{
+		LangRU: "Некорректное значение параметра %s",
+		LangEN: "Invalid value of parameter %s",
+	}},
+	CodeUnknownComponent: {http.StatusBadRequest, map[string]string{
+		LangRU: "Неизвестный компонент: %s",
+		LangEN: "Unknown component: %s",
+	}},
+	CodeMissingCredentials: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Отсутствуют учетные данные",
+		LangEN: "Credentials are missing",
+	}},
+	CodeMalformedCredentials: {http.StatusBadRequest, map[string]string{
+		LangRU: "Некорректный запрос авторизации",
+		LangEN: "Malformed authorization request",
+	}},
+	CodeInvalidCredentials: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Неверное имя пользователя или пароль",
+		LangEN: "Invalid username or password",
+	}},
+	CodeInvalidToken: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Недействительный токен",
+		LangEN: "The access token is invalid",
+	}},
+	CodeTokenExpired: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Срок действия токена истек",
+		LangEN: "The access token has expired",
+	}},
+	CodeTokenRevoked: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Токен отозван",
+		LangEN: "The access token has been revoked",
+	}},
+	CodeInvalidCSRFToken: {http.StatusForbidden, map[string]string{
+		LangRU: "Недействительный CSRF токен",
+		LangEN: "Invalid CSRF token",
+	}},
+	CodeClientCertRequired: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Требуется клиентский сертификат",
+		LangEN: "A client certificate is required",
+	}},
+	CodeInvalidAdminToken: {http.StatusUnauthorized, map[string]string{
+		LangRU: "Недействительный административный токен",
+		LangEN: "Invalid administrative token",
+	}},
+	CodeAdminNotConfigured: {http.StatusForbidden, map[string]string{
+		LangRU: "Административный доступ не настроен",
+		LangEN: "Administrative access is not configured",
+	}},
+	CodeAccountDisabled: {http.StatusForbidden, map[string]string{
+		LangRU: "Учетная запись отключена",
+		LangEN: "The account is disabled",
+	}},
+	CodePasswordResetRequired: {http.StatusForbidden, map[string]string{
+		LangRU: "Требуется смена пароля",
+		LangEN: "A password reset is required",
+	}},
+	CodeNotFound: {http.StatusNotFound, map[string]string{
+		LangRU: "Ресурс не найден",
+		LangEN: "Resource not found",
+	}},
+	CodeUserNotFound: {http.StatusNotFound, map[string]string{
+		LangRU: "Пользователь не найден",
+		LangEN: "User not found",
+	}},
+	CodeRateLimited: {http.StatusTooManyRequests, map[string]string{
+		LangRU: "Слишком много запросов, повторите позже",
+		LangEN: "Too many requests, try again later",
+	}},
+	CodeLoginLocked: {http.StatusTooManyRequests, map[string]string{
+		LangRU: "Слишком много неудачных попыток входа, повторите позже",
+		LangEN: "Too many failed login attempts, try again later",
+	}},
+	CodeBackendUnavailable: {http.StatusServiceUnavailable, map[string]string{
+		LangRU: "API базы данных пользователей недоступен",
+		LangEN: "The user database API is unavailable",
+	}},
+	CodeRateLimitUnavailable: {http.StatusServiceUnavailable, map[string]string{
+		LangRU: "Хранилище ограничения частоты запросов недоступно",
+		LangEN: "The rate limit store is unavailable",
+	}},
+	CodeSessionStoreUnavailable: {http.StatusServiceUnavailable, map[string]string{
+		LangRU: "Хранилище состояния сессий недоступно",
+		LangEN: "The session state store is unavailable",
+	}},
+	CodeInternal: {http.StatusInternalServerError, map[string]string{
+		LangRU: "Внутренняя ошибка сервиса",
+		LangEN: "Internal server error",
+	}},
+}
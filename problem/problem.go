@@ -0,0 +1,101 @@
+// Файл: problem/problem.go
+package problem
+
+import (
+	"fmt"
+
+	"auth-service/logger"
+	"auth-service/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// ContentType - тип содержимого ответов с ошибкой (RFC 7807)
+const ContentType = "application/problem+json"
+
+// TypePrefix - префикс URI типа ошибки; полный URI - префикс и код
+const TypePrefix = "urn:auth-service:error:"
+
+// defaultLanguage используется, если клиент не указал поддерживаемый язык
+const defaultLanguage = LangRU
+
+var matcher = language.NewMatcher([]language.Tag{language.Russian, language.English})
+
+// Status возвращает код HTTP ответа для кода ошибки
+func Status(code Code) int {
+	return lookup(code).status
+}
+
+// Message возвращает сообщение ошибки на языке lang
+func Message(code Code, lang string, args ...any) string {
+	def := lookup(code)
+	template, ok := def.messages[lang]
+	if !ok {
+		template = def.messages[defaultLanguage]
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// Language выбирает язык сообщений по заголовку Accept-Language
+func Language(c *gin.Context) string {
+	if c.Request == nil {
+		return defaultLanguage
+	}
+	tag, _ := language.MatchStrings(matcher, c.GetHeader("Accept-Language"))
+	if base, _ := tag.Base(); base.String() == LangEN {
+		return LangEN
+	}
+	return defaultLanguage
+}
+
+// New создает описание ошибки на языке запроса
+func New(c *gin.Context, code Code, args ...any) models.ErrorResponse {
+	title := Message(code, Language(c), args...)
+	response := models.ErrorResponse{
+		Type:   TypePrefix + string(code),
+		Title:  title,
+		Status: Status(code),
+		Code:   string(code),
+		Error:  title,
+	}
+	// Запрос отсутствует, например, в контексте, созданном вне обработки HTTP запроса
+	if c.Request != nil {
+		if c.Request.URL != nil {
+			response.Instance = c.Request.URL.Path
+		}
+		response.RequestID = logger.RequestIDFromContext(c.Request.Context())
+	}
+	return response
+}
+
+// Abort завершает запрос ответом application/problem+json с сообщением на языке запроса
+func Abort(c *gin.Context, code Code, args ...any) {
+	Write(c, New(c, code, args...))
+}
+
+// AbortDetail завершает запрос так же, как Abort, дополняя ответ пояснением detail
+func AbortDetail(c *gin.Context, code Code, detail string, args ...any) {
+	response := New(c, code, args...)
+	response.Detail = detail
+	Write(c, response)
+}
+
+// Write отправляет подготовленное описание ошибки и прерывает цепочку обработчиков
+func Write(c *gin.Context, response models.ErrorResponse) {
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", Language(c))
+	c.Header("Vary", "Accept-Language")
+	c.AbortWithStatusJSON(response.Status, response)
+}
+
+// lookup возвращает описание кода; неизвестные коды считаются внутренней ошибкой
+func lookup(code Code) definition {
+	if def, ok := catalog[code]; ok {
+		return def
+	}
+	return catalog[CodeInternal]
+}
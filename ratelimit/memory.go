@@ -0,0 +1,72 @@
+// Файл: ratelimit/memory.go
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval задает, как часто из памяти удаляются заполненные корзины
+const sweepInterval = time.Minute
+
+// bucket - состояние одной корзины
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // Момент, после которого корзина заполнена и ее можно удалить
+}
+
+// MemoryStore хранит корзины в памяти процесса. Подходит для одной реплики:
+// при нескольких репликах каждая ведет собственный учет.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore создает пустое хранилище в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// Take реализует Store
+func (s *MemoryStore) Take(_ context.Context, key string, rate float64, burst int) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	tokens, allowed := refill(b.tokens, now.Sub(b.updated), rate, burst)
+	b.tokens, b.updated = tokens, now
+	r := result(tokens, allowed, rate, burst)
+	b.full = now.Add(r.Reset)
+	return r, nil
+}
+
+// Len возвращает число корзин в памяти
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// sweep удаляет заполненные корзины: новая корзина для того же ключа будет такой же
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,44 @@
+// Файл: ratelimit/memory_test.go
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store { return NewMemoryStore() })
+}
+
+func TestMemoryStoreEvictsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	take(t, store, "full", 1000, 1)      // Заполнится через 1ms
+	take(t, store, "draining", 0.001, 2) // Заполнится через 1000s
+	time.Sleep(10 * time.Millisecond)
+
+	// До истечения sweepInterval корзины не удаляются
+	take(t, store, "other", 1, 1)
+	if n := store.Len(); n != 3 {
+		t.Fatalf("корзин %d до очистки, ожидалось 3", n)
+	}
+
+	store.mu.Lock()
+	store.lastSweep = time.Now().Add(-sweepInterval)
+	store.mu.Unlock()
+	take(t, store, "trigger", 1, 1)
+
+	if n := store.Len(); n != 3 {
+		t.Fatalf("корзин %d после очистки, ожидалось 3", n)
+	}
+	if _, ok := store.buckets["full"]; ok {
+		t.Error("заполненная корзина не удалена")
+	}
+	if _, ok := store.buckets["draining"]; !ok {
+		t.Error("удалена корзина, которая еще не заполнилась")
+	}
+
+	// Удаленная корзина создается заново полной
+	if r := take(t, store, "full", 1000, 1); !r.Allowed {
+		t.Fatalf("после удаления корзина не полна: %+v", r)
+	}
+}
@@ -0,0 +1,53 @@
+// Файл: ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Result описывает состояние корзины после попытки забрать из нее токен
+type Result struct {
+	Allowed    bool
+	Limit      int           // Емкость корзины
+	Remaining  int           // Оставшиеся токены
+	RetryAfter time.Duration // Через сколько появится токен, если запрос отклонен
+	Reset      time.Duration // Через сколько корзина заполнится полностью
+}
+
+// Store хранит корзины token bucket. Take атомарно пополняет корзину key
+// со скоростью rate токенов в секунду (не более burst) и забирает один токен.
+type Store interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (Result, error)
+}
+
+// refill пополняет корзину за прошедшее время и забирает токен, если он есть
+func refill(tokens float64, elapsed time.Duration, rate float64, burst int) (float64, bool) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(burst), tokens+elapsed.Seconds()*rate)
+	}
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+// result описывает корзину с tokens токенами
+func result(tokens float64, allowed bool, rate float64, burst int) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(burst) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return r
+}
+
+// seconds переводит дробное число секунд в длительность
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,89 @@
+// Файл: ratelimit/ratelimit_test.go
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testStore проверяет поведение, общее для всех реализаций Store
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Run("burst", func(t *testing.T) {
+		store := newStore(t)
+		for i := 0; i < 5; i++ {
+			r := take(t, store, "burst", 1, 5)
+			if !r.Allowed || r.Limit != 5 || r.Remaining != 4-i {
+				t.Fatalf("запрос %d: %+v, ожидалось разрешение с остатком %d", i+1, r, 4-i)
+			}
+		}
+
+		r := take(t, store, "burst", 1, 5)
+		if r.Allowed || r.Remaining != 0 {
+			t.Fatalf("запрос сверх емкости: %+v, ожидался отказ", r)
+		}
+		if r.RetryAfter <= 0 || r.RetryAfter > time.Second {
+			t.Errorf("RetryAfter %s, ожидалось (0, 1s]", r.RetryAfter)
+		}
+		if r.Reset <= 4*time.Second || r.Reset > 5*time.Second {
+			t.Errorf("Reset %s, ожидалось (4s, 5s]", r.Reset)
+		}
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		store := newStore(t)
+		take(t, store, "refill", 20, 2)
+		take(t, store, "refill", 20, 2)
+
+		r := take(t, store, "refill", 20, 2)
+		if r.Allowed {
+			t.Fatalf("пустая корзина разрешила запрос: %+v", r)
+		}
+		if r.RetryAfter <= 0 || r.RetryAfter > 50*time.Millisecond {
+			t.Fatalf("RetryAfter %s, ожидалось (0, 50ms]", r.RetryAfter)
+		}
+
+		time.Sleep(r.RetryAfter + 10*time.Millisecond)
+		if r := take(t, store, "refill", 20, 2); !r.Allowed {
+			t.Fatalf("корзина не пополнилась через RetryAfter: %+v", r)
+		}
+
+		// Корзина не пополняется сверх емкости
+		time.Sleep(150 * time.Millisecond)
+		if r := take(t, store, "refill", 20, 2); !r.Allowed || r.Remaining != 1 {
+			t.Fatalf("после простоя: %+v, ожидался остаток 1 при емкости 2", r)
+		}
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		store := newStore(t)
+		take(t, store, "first", 1, 1)
+		if r := take(t, store, "first", 1, 1); r.Allowed {
+			t.Fatalf("пустая корзина разрешила запрос: %+v", r)
+		}
+		if r := take(t, store, "second", 1, 1); !r.Allowed {
+			t.Fatalf("корзины разных ключей не независимы: %+v", r)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		store := newStore(t)
+		r := take(t, store, "reset", 2, 10)
+		if r.Reset != 500*time.Millisecond {
+			t.Errorf("Reset %s после одного запроса, ожидалось 500ms", r.Reset)
+		}
+		if r.RetryAfter != 0 {
+			t.Errorf("RetryAfter %s у разрешенного запроса, ожидался 0", r.RetryAfter)
+		}
+	})
+}
+
+// take забирает токен и завершает тест при ошибке хранилища
+func take(t *testing.T, store Store, key string, rate float64, burst int) Result {
+	t.Helper()
+	r, err := store.Take(context.Background(), key, rate, burst)
+	if err != nil {
+		t.Fatalf("Take(%s): %v", key, err)
+	}
+	return r
+}
@@ -0,0 +1,74 @@
+// Файл: ratelimit/redis.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript пополняет корзину и забирает токен атомарно на стороне Redis.
+// Время передается клиентом, чтобы скрипт не зависел от TIME и работал
+// с совместимыми хранилищами; расхождение часов реплик влияет только на скорость пополнения.
+// Ключ живет, пока корзина не заполнится.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(math.max(now, ts)))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore хранит корзины в Redis и дает общий учет для всех реплик
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore создает хранилище с ключами вида <prefix>ratelimit:<key>
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix + "ratelimit:"}
+}
+
+// Take реализует Store
+func (s *RedisStore) Take(ctx context.Context, key string, rate float64, burst int) (Result, error) {
+	now := time.Now().UnixMilli()
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		strconv.FormatFloat(rate, 'g', -1, 64), burst, now).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ошибка выполнения скрипта ограничения частоты: %w", err)
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("неожиданный ответ скрипта ограничения частоты: %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	raw, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("некорректное число токенов %q: %w", raw, err)
+	}
+	return result(tokens, allowed == 1, rate, burst), nil
+}
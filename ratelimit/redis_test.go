@@ -0,0 +1,60 @@
+// Файл: ratelimit/redis_test.go
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newMiniredisStore создает RedisStore поверх miniredis
+func newMiniredisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, "auth:"), server
+}
+
+func TestRedisStore(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		store, _ := newMiniredisStore(t)
+		return store
+	})
+}
+
+func TestRedisStoreTTL(t *testing.T) {
+	store, server := newMiniredisStore(t)
+	const key = "auth:ratelimit:ttl"
+
+	take(t, store, "ttl", 1, 5)
+	// Ключ живет, пока корзина не заполнится (1s), и еще секунду
+	if ttl := server.TTL(key); ttl != 2*time.Second {
+		t.Fatalf("TTL %s, ожидалось 2s", ttl)
+	}
+
+	take(t, store, "ttl", 1, 5)
+	if ttl := server.TTL(key); ttl <= 2*time.Second || ttl > 3*time.Second {
+		t.Fatalf("TTL %s после второго запроса, ожидалось (2s, 3s]", ttl)
+	}
+
+	server.FastForward(3 * time.Second)
+	if server.Exists(key) {
+		t.Fatal("ключ заполнившейся корзины не истек")
+	}
+	if r := take(t, store, "ttl", 1, 5); !r.Allowed || r.Remaining != 4 {
+		t.Fatalf("после истечения ключа: %+v, ожидалась полная корзина", r)
+	}
+}
+
+func TestRedisStoreUnavailable(t *testing.T) {
+	store, server := newMiniredisStore(t)
+	server.Close()
+
+	if _, err := store.Take(context.Background(), "down", 1, 1); err == nil {
+		t.Fatal("ожидалась ошибка недоступного Redis")
+	}
+}
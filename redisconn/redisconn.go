@@ -0,0 +1,40 @@
+// Файл: redisconn/redisconn.go
+package redisconn
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"auth-service/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// New создает клиент Redis по разделу redis конфигурации. Подключение
+// выполняется лениво, доступность проверяет Ping.
+func New(cfg config.RedisConfig) *redis.Client {
+	opts := &redis.Options{
+		Addr:        cfg.Address,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		DialTimeout: cfg.DialTimeout.Std(),
+	}
+	if cfg.TLS {
+		host, _, _ := net.SplitHostPort(cfg.Address)
+		opts.TLSConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+	return redis.NewClient(opts)
+}
+
+// Ping проверяет доступность Redis для /readyz
+func Ping(client *redis.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := client.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("redis недоступен: %w", err)
+		}
+		return nil
+	}
+}
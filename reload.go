@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/logger"
+)
+
+// configReloader перечитывает конфигурацию и атомарно применяет изменяемые параметры
+type configReloader struct {
+	mu     sync.Mutex
+	args   []string
+	appCtx *handlers.AppContext
+}
+
+// reload загружает конфигурацию заново. Некорректная конфигурация отклоняется,
+// а параметры, требующие перезапуска, сохраняются прежними.
+func (r *configReloader) reload(reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log := r.appCtx.Logger
+	current := r.appCtx.Config()
+
+	next, _, err := config.Load(r.args)
+	if err != nil {
+		log.Error("Перезагрузка конфигурации (%s) отклонена, продолжаем с прежней: %v", reason, err)
+		return err
+	}
+
+	changes := config.Diff(current, next)
+	if len(changes) == 0 {
+		log.Info("Перезагрузка конфигурации (%s): изменений нет", reason)
+		return nil
+	}
+
+	next.KeepRestartOnly(current)
+	for _, change := range changes {
+		if config.IsRestartOnly(change.Path) {
+			log.Warn("Параметр %s изменен (%s → %s), но будет применен только после перезапуска",
+				change.Path, change.Old, change.New)
+		} else {
+			log.Info("Параметр %s изменен: %s → %s", change.Path, change.Old, change.New)
+		}
+	}
+
+	r.appCtx.SetConfig(next)
+	log.SetBaseLevel(logger.ParseLevel(next.LogLevel))
+
+	log.Info("Конфигурация перезагружена (%s)", reason)
+	return nil
+}
+
+// watch отслеживает изменения файла конфигурации и перезагружает ее.
+// Интервал проверки берется из текущей конфигурации; при нулевом интервале
+// слежение приостанавливается до его изменения через SIGHUP.
+func (r *configReloader) watch(stop <-chan struct{}) {
+	var lastMod time.Time
+	var lastSize int64
+	if info, err := os.Stat(r.appCtx.Config().SourcePath); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
+	for {
+		interval := r.appCtx.Config().ConfigWatchInterval.Std()
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		cfg := r.appCtx.Config()
+		if cfg.ConfigWatchInterval <= 0 || cfg.SourcePath == "" {
+			continue
+		}
+
+		info, err := os.Stat(cfg.SourcePath)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+			continue
+		}
+		lastMod, lastSize = info.ModTime(), info.Size()
+
+		r.reload("изменение файла " + cfg.SourcePath)
+	}
+}
@@ -0,0 +1,144 @@
+// Файл: sdk/client.go
+
+// Package sdk - клиент API сервиса аутентификации для Go приложений:
+// вход, выпуск, проверка и обновление токенов, выход, а также источник
+// токенов, совместимый с oauth2.TokenSource.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"auth-service/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/oauth2"
+)
+
+// defaultTimeout - таймаут HTTP клиента по умолчанию
+const defaultTimeout = 10 * time.Second
+
+// maxResponseSize ограничивает размер ответов сервиса
+const maxResponseSize = 1 << 20
+
+// Client выполняет запросы к API сервиса аутентификации
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient создает клиент для сервиса по адресу baseURL, например https://auth.example.com
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// Login выполняет вход по имени пользователя и паролю (POST /login)
+func (c *Client) Login(ctx context.Context, username, password string) (*oauth2.Token, error) {
+	body, err := json.Marshal(models.User{Username: username, Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка маршалинга запроса: %w", err)
+	}
+
+	var response models.TokenResponse
+	if err := c.do(ctx, "/login", "application/json", bytes.NewReader(body), "", &response); err != nil {
+		return nil, err
+	}
+	return newToken(response)
+}
+
+// CreateToken выпускает токен по данным формы (POST /token/create)
+func (c *Client) CreateToken(ctx context.Context, username, password string) (*oauth2.Token, error) {
+	form := url.Values{"username": {username}, "password": {password}}
+
+	var response models.TokenResponse
+	if err := c.do(ctx, "/token/create", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), "", &response); err != nil {
+		return nil, err
+	}
+	return newToken(response)
+}
+
+// Verify проверяет токен доступа (POST /token/verify)
+func (c *Client) Verify(ctx context.Context, accessToken string) (*models.TokenVerifyResponse, error) {
+	var response models.TokenVerifyResponse
+	if err := c.do(ctx, "/token/verify", "", nil, accessToken, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Refresh обменивает действующий токен доступа на новый (POST /token/refresh).
+// Прежний токен после обновления перестает действовать.
+func (c *Client) Refresh(ctx context.Context, accessToken string) (*oauth2.Token, error) {
+	var response models.TokenResponse
+	if err := c.do(ctx, "/token/refresh", "", nil, accessToken, &response); err != nil {
+		return nil, err
+	}
+	return newToken(response)
+}
+
+// Logout завершает сессию и отзывает токен доступа (POST /logout)
+func (c *Client) Logout(ctx context.Context, accessToken string) error {
+	return c.do(ctx, "/logout", "", nil, accessToken, &models.Message{})
+}
+
+// do выполняет POST запрос и декодирует успешный ответ в result.
+// Ответы с ошибкой возвращаются как *Error.
+func (c *Client) do(ctx context.Context, path, contentType string, body io.Reader, accessToken string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка сетевого запроса: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody := io.LimitReader(resp.Body, maxResponseSize)
+
+	if resp.StatusCode != http.StatusOK {
+		return newError(resp.StatusCode, respBody)
+	}
+	if err := json.NewDecoder(respBody).Decode(result); err != nil {
+		return fmt.Errorf("ошибка декодирования ответа: %w", err)
+	}
+	return nil
+}
+
+// newToken преобразует ответ сервиса в oauth2.Token. Срок действия берется из claim exp
+// без проверки подписи: клиенту он нужен только для своевременного обновления.
+func newToken(response models.TokenResponse) (*oauth2.Token, error) {
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("ответ не содержит токен доступа (token_type %q)", response.TokenType)
+	}
+
+	token := &oauth2.Token{AccessToken: response.AccessToken, TokenType: response.TokenType}
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(response.AccessToken, claims); err != nil {
+		return nil, fmt.Errorf("некорректный токен в ответе: %w", err)
+	}
+	if claims.ExpiresAt != nil {
+		token.Expiry = claims.ExpiresAt.Time
+	}
+	return token, nil
+}
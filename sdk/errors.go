@@ -0,0 +1,62 @@
+// Файл: sdk/errors.go
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"auth-service/models"
+)
+
+// Категории ошибок, с которыми *Error совпадает в errors.Is
+var (
+	ErrBadRequest      = errors.New("некорректный запрос")
+	ErrUnauthorized    = errors.New("не авторизован")
+	ErrForbidden       = errors.New("доступ запрещен")
+	ErrTooManyRequests = errors.New("слишком много запросов")
+	ErrServer          = errors.New("ошибка сервиса аутентификации")
+)
+
+// Error описывает ответ сервиса с ошибкой (models.ErrorResponse)
+type Error struct {
+	StatusCode int
+	Code       string // Машиночитаемый код, например invalid_credentials или token_expired
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("сервис аутентификации вернул %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("сервис аутентификации вернул %d (%s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is сопоставляет код ответа с категориями ErrBadRequest, ErrUnauthorized и т.д.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// newError создает *Error из тела ответа; если тело не в формате ErrorResponse,
+// используется стандартное описание кода
+func newError(status int, body io.Reader) *Error {
+	var response models.ErrorResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil || response.Error == "" {
+		response.Error = http.StatusText(status)
+	}
+	return &Error{StatusCode: status, Code: response.Code, Message: response.Error}
+}
@@ -0,0 +1,92 @@
+// Файл: sdk/token_source.go
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultRefreshBefore задает, за сколько до истечения токен обновляется
+const defaultRefreshBefore = time.Minute
+
+// TokenSource выдает действующий токен доступа: выполняет вход при первом запросе,
+// обновляет токен заранее до истечения и повторяет вход, если обновить не удалось.
+// Реализует oauth2.TokenSource и безопасен для параллельного использования.
+type TokenSource struct {
+	client   *Client
+	username string
+	password string
+
+	// За сколько до истечения обновлять токен; по умолчанию 1 минута
+	RefreshBefore time.Duration
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+var _ oauth2.TokenSource = (*TokenSource)(nil)
+
+// PasswordTokenSource создает источник токенов, входящий с указанными учетными данными.
+// Для HTTP клиента с автоматической авторизацией: oauth2.NewClient(ctx, ts).
+func (c *Client) PasswordTokenSource(username, password string) *TokenSource {
+	return &TokenSource{client: c, username: username, password: password, RefreshBefore: defaultRefreshBefore}
+}
+
+// Token возвращает действующий токен доступа
+func (ts *TokenSource) Token() (*oauth2.Token, error) {
+	return ts.TokenContext(context.Background())
+}
+
+// TokenContext возвращает действующий токен доступа, выполняя запросы с контекстом ctx
+func (ts *TokenSource) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+	if ts.token != nil && (ts.token.Expiry.IsZero() || now.Add(ts.RefreshBefore).Before(ts.token.Expiry)) {
+		return copyToken(ts.token), nil
+	}
+
+	// Токен еще действует - обновляем его, иначе или при ошибке обновления входим заново
+	if ts.token != nil && now.Before(ts.token.Expiry) {
+		token, err := ts.client.Refresh(ctx, ts.token.AccessToken)
+		if err == nil {
+			ts.token = token
+			return copyToken(token), nil
+		}
+		if !errors.Is(err, ErrUnauthorized) && !errors.Is(err, ErrBadRequest) {
+			// Сервис недоступен: прежний токен еще можно использовать
+			return copyToken(ts.token), nil
+		}
+	}
+
+	token, err := ts.client.Login(ctx, ts.username, ts.password)
+	if err != nil {
+		return nil, err
+	}
+	ts.token = token
+	return copyToken(token), nil
+}
+
+// Logout завершает сессию текущего токена; следующий запрос токена выполнит вход заново
+func (ts *TokenSource) Logout(ctx context.Context) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token == nil {
+		return nil
+	}
+	err := ts.client.Logout(ctx, ts.token.AccessToken)
+	ts.token = nil
+	return err
+}
+
+// copyToken возвращает копию токена, чтобы вызывающий код не изменял сохраненный
+func copyToken(token *oauth2.Token) *oauth2.Token {
+	copied := *token
+	return &copied
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"auth-service/config"
+	"auth-service/handlers"
+	"auth-service/tlsconfig"
+)
+
+// listener связывает HTTP сервер с адресом, на котором он принимает соединения
+type listener struct {
+	name    string
+	address string // host:port или unix:/путь/к/сокету
+	srv     *http.Server
+}
+
+// newHTTPServer создает HTTP сервер с таймаутами из конфигурации
+func newHTTPServer(cfg *config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.ServerPort),
+		Handler:           handler,
+		ReadTimeout:       cfg.ServerReadTimeout.Std(),
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout.Std(),
+		WriteTimeout:      cfg.ServerWriteTimeout.Std(),
+		IdleTimeout:       cfg.ServerIdleTimeout.Std(),
+	}
+}
+
+// enableTLS включает на сервере HTTPS с перезагрузкой сертификатов при изменении файлов
+// и добавляет в /readyz проверку срока действия сертификата под именем checkName
+func enableTLS(srv *http.Server, cfg config.TLSConfig, checkName string, appCtx *handlers.AppContext, stop <-chan struct{}) error {
+	certs, err := tlsconfig.New(cfg, appCtx.Logger)
+	if err != nil {
+		return err
+	}
+	if srv.TLSConfig, err = certs.TLSConfig(); err != nil {
+		return err
+	}
+
+	go certs.Watch(stop)
+	appCtx.Health.Register(checkName, func(context.Context) error {
+		return certs.Check()
+	})
+	return nil
+}
+
+// listen открывает адрес listener. Для unix сокета удаляется файл, оставшийся
+// от предыдущего запуска, а доступ к новому ограничивается владельцем и группой.
+func (l *listener) listen() (net.Listener, error) {
+	path, isSocket := strings.CutPrefix(l.address, config.UnixSocketPrefix)
+	if !isSocket {
+		return net.Listen("tcp", l.address)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0660); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// serve запускает серверы и блокируется до ошибки одного из них или сигнала SIGINT/SIGTERM.
+// При сигнале сервис переводится в неготовность, через shutdown_delay перестает
+// принимать соединения и до shutdown_timeout ждет завершения текущих запросов.
+func serve(appCtx *handlers.AppContext, listeners ...*listener) error {
+	netListeners := make([]net.Listener, 0, len(listeners))
+	for _, l := range listeners {
+		ln, err := l.listen()
+		if err != nil {
+			for _, opened := range netListeners {
+				opened.Close()
+			}
+			return fmt.Errorf("не удалось открыть %s на %s: %w", l.name, l.address, err)
+		}
+		netListeners = append(netListeners, ln)
+	}
+
+	serverErr := make(chan error, len(listeners))
+	for i, l := range listeners {
+		go func(l *listener, ln net.Listener) {
+			var err error
+			if l.srv.TLSConfig != nil {
+				// Сертификат задается через TLSConfig.GetCertificate
+				err = l.srv.ServeTLS(ln, "", "")
+			} else {
+				err = l.srv.Serve(ln)
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- fmt.Errorf("%s: %w", l.name, err)
+			}
+		}(l, netListeners[i])
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log := appCtx.Logger
+	cfg := appCtx.Config()
+
+	select {
+	case err := <-serverErr:
+		shutdownAll(context.Background(), listeners)
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Получен сигнал остановки, сервис переведен в неготовность")
+	appCtx.StartDraining()
+
+	if delay := cfg.ShutdownDelay.Std(); delay > 0 {
+		log.Info("Ожидание %s перед остановкой приема соединений", delay)
+		time.Sleep(delay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout.Std())
+	defer cancel()
+
+	log.Info("Ожидание завершения текущих запросов (не более %s)", cfg.ShutdownTimeout.Std())
+	if err := shutdownAll(shutdownCtx, listeners); err != nil {
+		return fmt.Errorf("текущие запросы не завершились за отведенное время: %w", err)
+	}
+
+	log.Info("Все запросы завершены, сервер остановлен")
+	return nil
+}
+
+// shutdownAll одновременно останавливает все серверы, дожидаясь завершения текущих запросов
+func shutdownAll(ctx context.Context, listeners []*listener) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(listeners))
+	for i, l := range listeners {
+		wg.Add(1)
+		go func(i int, l *listener) {
+			defer wg.Done()
+			if err := l.srv.Shutdown(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", l.name, err)
+			}
+		}(i, l)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"auth-service/handlers"
+	"auth-service/logger"
+)
+
+// handleSignals обрабатывает служебные сигналы:
+// SIGUSR1 переключает общий уровень логирования на debug и обратно,
+// SIGHUP перезагружает конфигурацию
+func handleSignals(appCtx *handlers.AppContext, reloader *configReloader) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGHUP)
+
+	go func() {
+		for sig := range signals {
+			switch sig {
+			case syscall.SIGHUP:
+				reloader.reload("SIGHUP")
+			case syscall.SIGUSR1:
+				toggleDebugLevel(appCtx)
+			}
+		}
+	}()
+}
+
+// toggleDebugLevel переключает общий уровень логирования на debug и обратно
+func toggleDebugLevel(appCtx *handlers.AppContext) {
+	log := appCtx.Logger
+	if log.Level("") == logger.DEBUG {
+		log.ResetLevel("")
+		log.Warn("SIGUSR1: уровень логирования возвращен к значению из конфигурации")
+		return
+	}
+
+	revertAfter := appCtx.Config().LogLevelRevertAfter.Std()
+	log.SetLevel("", logger.DEBUG, revertAfter)
+	log.Warn("SIGUSR1: уровень логирования переключен на debug на %s", revertAfter)
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "auth-service/handlers"
+
+// handleSignals ничего не делает: SIGUSR1 и SIGHUP недоступны на Windows,
+// конфигурация перезагружается только при изменении файла
+func handleSignals(appCtx *handlers.AppContext, reloader *configReloader) {}
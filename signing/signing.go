@@ -0,0 +1,172 @@
+// Файл: signing/signing.go
+package signing
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"auth-service/config"
+	"auth-service/jwks"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AlgorithmHS256 - алгоритм подписи общим секретом
+const AlgorithmHS256 = "HS256"
+
+// minRSABits задает минимальную длину ключа RSA
+const minRSABits = 2048
+
+// verificationKey - открытый ключ, принимаемый при проверке подписи
+type verificationKey struct {
+	alg string
+	pub crypto.PublicKey
+}
+
+// Keys подписывает токены и проверяет их подпись. Асимметричные ключи публикуются в JWKS,
+// чтобы другие сервисы могли проверять токены без обращения к сервису аутентификации.
+type Keys struct {
+	Algorithm string
+
+	secret  []byte
+	private crypto.Signer
+	keyID   string
+	verify  map[string]verificationKey
+	set     jwks.Set
+}
+
+// NewHMAC создает ключи для подписи HS256 общим секретом
+func NewHMAC(secret string) *Keys {
+	return &Keys{Algorithm: AlgorithmHS256, secret: []byte(secret), set: jwks.Set{Keys: []jwks.Key{}}}
+}
+
+// Load загружает закрытый ключ подписи и прежние ключи из файлов, указанных в конфигурации
+func Load(cfg config.SigningConfig) (*Keys, error) {
+	signer, err := readKey(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("ключ подписи %s: %w", cfg.KeyFile, err)
+	}
+	private, ok := signer.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("ключ подписи %s: требуется закрытый ключ", cfg.KeyFile)
+	}
+
+	k := &Keys{private: private, verify: make(map[string]verificationKey), set: jwks.Set{Keys: []jwks.Key{}}}
+	if k.keyID, err = k.add(private.Public()); err != nil {
+		return nil, fmt.Errorf("ключ подписи %s: %w", cfg.KeyFile, err)
+	}
+	k.Algorithm = k.verify[k.keyID].alg
+
+	for _, path := range cfg.PreviousKeyFiles {
+		key, err := readKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("прежний ключ %s: %w", path, err)
+		}
+		if private, ok := key.(crypto.Signer); ok {
+			key = private.Public()
+		}
+		if _, err := k.add(key); err != nil {
+			return nil, fmt.Errorf("прежний ключ %s: %w", path, err)
+		}
+	}
+	return k, nil
+}
+
+// add регистрирует открытый ключ для проверки подписи и публикации в JWKS
+func (k *Keys) add(pub crypto.PublicKey) (string, error) {
+	if rsaKey, ok := pub.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSABits {
+		return "", fmt.Errorf("ключ RSA короче %d бит", minRSABits)
+	}
+	key, err := jwks.NewKey(pub)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := k.verify[key.Kid]; !exists {
+		k.verify[key.Kid] = verificationKey{alg: key.Alg, pub: pub}
+		k.set.Keys = append(k.set.Keys, key)
+	}
+	return key.Kid, nil
+}
+
+// Sign подписывает claims текущим ключом; асимметричная подпись содержит kid в заголовке
+func (k *Keys) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	if k.private == nil {
+		return token.SignedString(k.secret)
+	}
+	token.Header["kid"] = k.keyID
+	return token.SignedString(k.private)
+}
+
+// Parse разбирает токен и проверяет его подпись одним из принимаемых ключей
+func (k *Keys) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, k.keyFunc, append(options, jwt.WithValidMethods(k.methods()))...)
+}
+
+// keyFunc выбирает ключ проверки подписи по заголовку токена
+func (k *Keys) keyFunc(token *jwt.Token) (any, error) {
+	if k.private == nil {
+		return k.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verify[kid]
+	if !ok {
+		return nil, errors.New("неизвестный ключ подписи")
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, errors.New("некорректный алгоритм подписи")
+	}
+	return key.pub, nil
+}
+
+// methods возвращает алгоритмы, которыми могут быть подписаны принимаемые токены
+func (k *Keys) methods() []string {
+	if k.private == nil {
+		return []string{k.Algorithm}
+	}
+	var methods []string
+	seen := make(map[string]bool)
+	for _, key := range k.verify {
+		if !seen[key.alg] {
+			seen[key.alg] = true
+			methods = append(methods, key.alg)
+		}
+	}
+	return methods
+}
+
+// JWKS возвращает открытые ключи проверки подписи; при подписи HS256 набор пуст
+func (k *Keys) JWKS() jwks.Set {
+	return k.set
+}
+
+// readKey читает закрытый или открытый ключ из PEM файла
+func readKey(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("файл не содержит PEM блок")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип PEM блока %q", block.Type)
+	}
+}
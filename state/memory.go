@@ -0,0 +1,155 @@
+// Файл: state/memory.go
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval задает, как часто из памяти удаляются истекшие записи
+const sweepInterval = time.Minute
+
+// attempts - счетчик неудачных попыток входа
+type attempts struct {
+	count int
+	reset time.Time
+}
+
+// MemoryStore хранит состояние в памяти процесса. Подходит для одной реплики:
+// при нескольких репликах выход на одной из них не виден остальным.
+type MemoryStore struct {
+	mu          sync.Mutex
+	revoked     map[string]time.Time // jti и семейства с префиксом вида записи
+	failures    map[string]attempts
+	subscribers []func(username string)
+	lastSweep   time.Time
+}
+
+// NewMemoryStore создает пустое хранилище в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		revoked:   make(map[string]time.Time),
+		failures:  make(map[string]attempts),
+		lastSweep: time.Now(),
+	}
+}
+
+// RevokeToken реализует Store
+func (s *MemoryStore) RevokeToken(_ context.Context, jti string, until time.Time) error {
+	s.revoke("token:"+jti, until)
+	return nil
+}
+
+// IsTokenRevoked реализует Store
+func (s *MemoryStore) IsTokenRevoked(_ context.Context, jti string) (bool, error) {
+	return s.isRevoked("token:" + jti), nil
+}
+
+// RevokeFamily реализует Store
+func (s *MemoryStore) RevokeFamily(_ context.Context, family string, until time.Time) error {
+	s.revoke("family:"+family, until)
+	return nil
+}
+
+// IsFamilyRevoked реализует Store
+func (s *MemoryStore) IsFamilyRevoked(_ context.Context, family string) (bool, error) {
+	return s.isRevoked("family:" + family), nil
+}
+
+// RecordLoginFailure реализует Store
+func (s *MemoryStore) RecordLoginFailure(_ context.Context, username string, window time.Duration) (int, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	a, ok := s.failures[username]
+	if !ok || now.After(a.reset) {
+		a = attempts{reset: now.Add(window)}
+	}
+	a.count++
+	s.failures[username] = a
+	return a.count, a.reset.Sub(now), nil
+}
+
+// LoginFailures реализует Store
+func (s *MemoryStore) LoginFailures(_ context.Context, username string) (int, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.failures[username]
+	if !ok || now.After(a.reset) {
+		return 0, 0, nil
+	}
+	return a.count, a.reset.Sub(now), nil
+}
+
+// ResetLoginFailures реализует Store
+func (s *MemoryStore) ResetLoginFailures(_ context.Context, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, username)
+	return nil
+}
+
+// Invalidate реализует Store: в пределах одной реплики обработчики вызываются сразу
+func (s *MemoryStore) Invalidate(_ context.Context, username string) error {
+	s.mu.Lock()
+	subscribers := s.subscribers
+	s.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(username)
+	}
+	return nil
+}
+
+// Subscribe реализует Store
+func (s *MemoryStore) Subscribe(fn func(username string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Close реализует Store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// revoke добавляет запись об отзыве, которая хранится до until
+func (s *MemoryStore) revoke(key string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(time.Now())
+	s.revoked[key] = until
+}
+
+// isRevoked проверяет наличие неистекшей записи об отзыве
+func (s *MemoryStore) isRevoked(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.revoked[key]
+	return ok && time.Now().Before(until)
+}
+
+// sweep удаляет истекшие записи не чаще sweepInterval
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	for key, until := range s.revoked {
+		if now.After(until) {
+			delete(s.revoked, key)
+		}
+	}
+	for username, a := range s.failures {
+		if now.After(a.reset) {
+			delete(s.failures, username)
+		}
+	}
+	s.lastSweep = now
+}
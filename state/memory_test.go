@@ -0,0 +1,39 @@
+// Файл: state/memory_test.go
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T) (Store, func(time.Duration)) {
+		return NewMemoryStore(), time.Sleep
+	})
+}
+
+func TestMemoryStoreSweepsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	store.RevokeToken(ctx, "expired", time.Now().Add(time.Millisecond))
+	store.RevokeFamily(ctx, "active", time.Now().Add(time.Hour))
+	store.RecordLoginFailure(ctx, "expired", time.Millisecond)
+	store.RecordLoginFailure(ctx, "active", time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	store.mu.Lock()
+	store.lastSweep = time.Now().Add(-sweepInterval)
+	store.mu.Unlock()
+	store.RevokeToken(ctx, "trigger", time.Now().Add(time.Hour))
+
+	if _, ok := store.revoked["token:expired"]; ok {
+		t.Error("истекший отзыв не удален")
+	}
+	if _, ok := store.failures["expired"]; ok {
+		t.Error("истекший счетчик попыток не удален")
+	}
+	if len(store.revoked) != 2 || len(store.failures) != 1 {
+		t.Errorf("после очистки %d отзывов и %d счетчиков, ожидалось 2 и 1", len(store.revoked), len(store.failures))
+	}
+}
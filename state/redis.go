@@ -0,0 +1,171 @@
+// Файл: state/redis.go
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// failureScript увеличивает счетчик неудачных попыток и при первой неудаче задает
+// срок его жизни, чтобы окно отсчитывалось от первой попытки
+var failureScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if count == 1 or ttl < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// RedisStore хранит состояние в Redis, общем для всех реплик. Сообщения Invalidate
+// рассылаются через pub/sub, чтобы реплики сразу сбрасывали локальный кеш.
+type RedisStore struct {
+	client  *redis.Client
+	prefix  string
+	channel string
+	pubsub  *redis.PubSub
+
+	mu          sync.Mutex
+	subscribers []func(username string)
+}
+
+// NewRedisStore создает хранилище с ключами вида <prefix>revoked:..., <prefix>login_failures:...
+// и подписывается на канал <prefix>invalidate
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	s := &RedisStore{client: client, prefix: prefix, channel: prefix + "invalidate"}
+	s.pubsub = client.Subscribe(context.Background(), s.channel)
+	go s.listen()
+	return s
+}
+
+// listen передает сообщения канала обработчикам до закрытия подписки.
+// После разрыва соединения клиент переподписывается сам; сообщения, отправленные
+// за время разрыва, теряются, и устаревшие записи кеша истекают по user_cache_ttl.
+func (s *RedisStore) listen() {
+	for msg := range s.pubsub.Channel() {
+		s.mu.Lock()
+		subscribers := s.subscribers
+		s.mu.Unlock()
+
+		for _, fn := range subscribers {
+			fn(msg.Payload)
+		}
+	}
+}
+
+// RevokeToken реализует Store
+func (s *RedisStore) RevokeToken(ctx context.Context, jti string, until time.Time) error {
+	return s.revoke(ctx, s.prefix+"revoked:token:"+jti, until)
+}
+
+// IsTokenRevoked реализует Store
+func (s *RedisStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.exists(ctx, s.prefix+"revoked:token:"+jti)
+}
+
+// RevokeFamily реализует Store
+func (s *RedisStore) RevokeFamily(ctx context.Context, family string, until time.Time) error {
+	return s.revoke(ctx, s.prefix+"revoked:family:"+family, until)
+}
+
+// IsFamilyRevoked реализует Store
+func (s *RedisStore) IsFamilyRevoked(ctx context.Context, family string) (bool, error) {
+	return s.exists(ctx, s.prefix+"revoked:family:"+family)
+}
+
+// RecordLoginFailure реализует Store
+func (s *RedisStore) RecordLoginFailure(ctx context.Context, username string, window time.Duration) (int, time.Duration, error) {
+	reply, err := failureScript.Run(ctx, s.client, []string{s.failuresKey(username)}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, unavailable(err)
+	}
+	if len(reply) != 2 {
+		return 0, 0, fmt.Errorf("неожиданный ответ скрипта учета попыток входа: %v", reply)
+	}
+	return int(reply[0]), time.Duration(reply[1]) * time.Millisecond, nil
+}
+
+// LoginFailures реализует Store
+func (s *RedisStore) LoginFailures(ctx context.Context, username string) (int, time.Duration, error) {
+	key := s.failuresKey(username)
+	pipe := s.client.Pipeline()
+	count := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, unavailable(err)
+	}
+
+	n, err := count.Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, unavailable(err)
+	}
+	return n, max(ttl.Val(), 0), nil
+}
+
+// ResetLoginFailures реализует Store
+func (s *RedisStore) ResetLoginFailures(ctx context.Context, username string) error {
+	if err := s.client.Del(ctx, s.failuresKey(username)).Err(); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+// Invalidate реализует Store; сообщение получают все подписанные реплики, включая текущую
+func (s *RedisStore) Invalidate(ctx context.Context, username string) error {
+	if err := s.client.Publish(ctx, s.channel, username).Err(); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+// Subscribe реализует Store
+func (s *RedisStore) Subscribe(fn func(username string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Close реализует Store; клиент Redis закрывает владелец
+func (s *RedisStore) Close() error {
+	return s.pubsub.Close()
+}
+
+// revoke сохраняет запись об отзыве со сроком жизни до until
+func (s *RedisStore) revoke(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.client.Set(ctx, key, 1, ttl).Err(); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+// exists проверяет наличие ключа
+func (s *RedisStore) exists(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, unavailable(err)
+	}
+	return n > 0, nil
+}
+
+// failuresKey возвращает ключ счетчика неудачных попыток входа
+func (s *RedisStore) failuresKey(username string) string {
+	return s.prefix + "login_failures:" + username
+}
+
+// unavailable отмечает ошибку Redis как недоступность хранилища
+func unavailable(err error) error {
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
@@ -0,0 +1,126 @@
+// Файл: state/redis_test.go
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newMiniredisStore создает RedisStore поверх server и ждет активации подписки
+func newMiniredisStore(t *testing.T, server *miniredis.Miniredis) *RedisStore {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store := NewRedisStore(client, "auth:")
+	t.Cleanup(func() {
+		store.Close()
+		client.Close()
+	})
+
+	// Подписка выполняется асинхронно; сообщения, опубликованные до нее, не доходят
+	subscribers := server.PubSubNumSub(store.channel)[store.channel]
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		if server.PubSubNumSub(store.channel)[store.channel] > subscribers {
+			return store
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("подписка на канал %s не активирована", store.channel)
+	return nil
+}
+
+func TestRedisStore(t *testing.T) {
+	testStore(t, func(t *testing.T) (Store, func(time.Duration)) {
+		server := miniredis.RunT(t)
+		return newMiniredisStore(t, server), server.FastForward
+	})
+}
+
+func TestRedisStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	store := newMiniredisStore(t, server)
+
+	store.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour))
+	store.RevokeFamily(ctx, "family-1", time.Now().Add(30*24*time.Hour))
+	store.RecordLoginFailure(ctx, "user123", 15*time.Minute)
+	store.RecordLoginFailure(ctx, "user123", 15*time.Minute)
+
+	checks := []struct {
+		key      string
+		min, max time.Duration
+	}{
+		{"auth:revoked:token:jti-1", time.Hour - time.Second, time.Hour},
+		{"auth:revoked:family:family-1", 30*24*time.Hour - time.Second, 30 * 24 * time.Hour},
+		{"auth:login_failures:user123", 15 * time.Minute, 15 * time.Minute},
+	}
+	for _, check := range checks {
+		if ttl := server.TTL(check.key); ttl < check.min || ttl > check.max {
+			t.Errorf("TTL %s = %s, ожидалось [%s, %s]", check.key, ttl, check.min, check.max)
+		}
+	}
+	if keys := server.Keys(); len(keys) != 3 {
+		t.Errorf("ключи %v, ожидалось 3", keys)
+	}
+}
+
+// Счетчик без срока жизни (например, после PERSIST) получает окно заново, а не живет вечно
+func TestRedisStoreFailureWithoutTTL(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	store := newMiniredisStore(t, server)
+
+	server.Set("auth:login_failures:user123", "5")
+	count, retry, err := store.RecordLoginFailure(ctx, "user123", time.Minute)
+	if err != nil || count != 6 || retry != time.Minute {
+		t.Fatalf("получено (%d, %s, %v), ожидалось (6, 1m, nil)", count, retry, err)
+	}
+	if ttl := server.TTL("auth:login_failures:user123"); ttl != time.Minute {
+		t.Fatalf("TTL %s, ожидалось 1m", ttl)
+	}
+}
+
+func TestRedisStoreInvalidateReachesAllReplicas(t *testing.T) {
+	server := miniredis.RunT(t)
+	first, second := newMiniredisStore(t, server), newMiniredisStore(t, server)
+
+	received := [2]chan string{make(chan string, 1), make(chan string, 1)}
+	first.Subscribe(func(username string) { received[0] <- username })
+	second.Subscribe(func(username string) { received[1] <- username })
+
+	if err := first.Invalidate(context.Background(), "user123"); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	expectInvalidation(t, received[0], "user123")
+	expectInvalidation(t, received[1], "user123")
+}
+
+func TestRedisStoreUnavailable(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	store := newMiniredisStore(t, server)
+	server.Close()
+
+	calls := map[string]func() error{
+		"RevokeToken":    func() error { return store.RevokeToken(ctx, "jti", time.Now().Add(time.Hour)) },
+		"IsTokenRevoked": func() error { _, err := store.IsTokenRevoked(ctx, "jti"); return err },
+		"RevokeFamily":   func() error { return store.RevokeFamily(ctx, "family", time.Now().Add(time.Hour)) },
+		"IsFamilyRevoked": func() error {
+			_, err := store.IsFamilyRevoked(ctx, "family")
+			return err
+		},
+		"RecordLoginFailure": func() error { _, _, err := store.RecordLoginFailure(ctx, "user123", time.Minute); return err },
+		"LoginFailures":      func() error { _, _, err := store.LoginFailures(ctx, "user123"); return err },
+		"ResetLoginFailures": func() error { return store.ResetLoginFailures(ctx, "user123") },
+		"Invalidate":         func() error { return store.Invalidate(ctx, "user123") },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("%s: ошибка %v, ожидалась ErrUnavailable", name, err)
+		}
+	}
+}
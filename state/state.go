@@ -0,0 +1,42 @@
+// Файл: state/state.go
+package state
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnavailable возвращается, если хранилище состояния недоступно
+var ErrUnavailable = errors.New("хранилище состояния сессий недоступно")
+
+// Store хранит состояние сессий, которое должно быть одинаковым на всех репликах:
+// отозванные токены доступа и семейства токенов обновления, счетчики неудачных
+// попыток входа, а также рассылает сообщения об изменении данных пользователей,
+// по которым реплики сбрасывают локальный кеш.
+type Store interface {
+	// RevokeToken отзывает токен по jti до истечения его срока действия until
+	RevokeToken(ctx context.Context, jti string, until time.Time) error
+	// IsTokenRevoked проверяет, отозван ли токен
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+
+	// RevokeFamily отзывает все токены обновления сессии до истечения последнего из них
+	RevokeFamily(ctx context.Context, family string, until time.Time) error
+	// IsFamilyRevoked проверяет, отозвано ли семейство токенов обновления
+	IsFamilyRevoked(ctx context.Context, family string) (bool, error)
+
+	// RecordLoginFailure учитывает неудачную попытку входа. Счетчик сбрасывается через
+	// window после первой неудачи; возвращаются число попыток и время до сброса.
+	RecordLoginFailure(ctx context.Context, username string, window time.Duration) (int, time.Duration, error)
+	// LoginFailures возвращает число неудачных попыток входа и время до сброса счетчика
+	LoginFailures(ctx context.Context, username string) (int, time.Duration, error)
+	// ResetLoginFailures сбрасывает счетчик после успешного входа
+	ResetLoginFailures(ctx context.Context, username string) error
+
+	// Invalidate сообщает всем репликам, включая текущую, что данные пользователя изменились
+	Invalidate(ctx context.Context, username string) error
+	// Subscribe регистрирует обработчик сообщений Invalidate
+	Subscribe(fn func(username string))
+
+	Close() error
+}
@@ -0,0 +1,145 @@
+// Файл: state/state_test.go
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// backend создает хранилище для теста; advance переводит время хранилища вперед
+type backend func(t *testing.T) (store Store, advance func(time.Duration))
+
+// testStore проверяет поведение, общее для всех реализаций Store
+func testStore(t *testing.T, newStore backend) {
+	ctx := context.Background()
+
+	t.Run("revoked tokens", func(t *testing.T) {
+		store, advance := newStore(t)
+		if err := store.RevokeToken(ctx, "jti-1", time.Now().Add(100*time.Millisecond)); err != nil {
+			t.Fatalf("RevokeToken: %v", err)
+		}
+		checkRevoked(t, store.IsTokenRevoked, "jti-1", true)
+		checkRevoked(t, store.IsTokenRevoked, "jti-2", false)
+		// Токены и семейства не пересекаются, даже если идентификаторы совпали
+		checkRevoked(t, store.IsFamilyRevoked, "jti-1", false)
+
+		// Запись хранится, пока токен мог бы действовать
+		advance(150 * time.Millisecond)
+		checkRevoked(t, store.IsTokenRevoked, "jti-1", false)
+	})
+
+	t.Run("revoked families", func(t *testing.T) {
+		store, advance := newStore(t)
+		if err := store.RevokeFamily(ctx, "family-1", time.Now().Add(100*time.Millisecond)); err != nil {
+			t.Fatalf("RevokeFamily: %v", err)
+		}
+		checkRevoked(t, store.IsFamilyRevoked, "family-1", true)
+		checkRevoked(t, store.IsFamilyRevoked, "family-2", false)
+		checkRevoked(t, store.IsTokenRevoked, "family-1", false)
+
+		advance(150 * time.Millisecond)
+		checkRevoked(t, store.IsFamilyRevoked, "family-1", false)
+	})
+
+	t.Run("expired revocation", func(t *testing.T) {
+		store, _ := newStore(t)
+		if err := store.RevokeToken(ctx, "old", time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("RevokeToken: %v", err)
+		}
+		checkRevoked(t, store.IsTokenRevoked, "old", false)
+	})
+
+	t.Run("login failures", func(t *testing.T) {
+		store, advance := newStore(t)
+		const window = 100 * time.Millisecond
+
+		if count, retry, err := store.LoginFailures(ctx, "user123"); err != nil || count != 0 || retry != 0 {
+			t.Fatalf("без неудач: (%d, %s, %v), ожидалось (0, 0, nil)", count, retry, err)
+		}
+
+		for want := 1; want <= 3; want++ {
+			count, retry, err := store.RecordLoginFailure(ctx, "user123", window)
+			if err != nil || count != want {
+				t.Fatalf("неудача %d: (%d, %v)", want, count, err)
+			}
+			if retry <= 0 || retry > window {
+				t.Fatalf("неудача %d: до сброса %s, ожидалось (0, %s]", want, retry, window)
+			}
+		}
+		if count, _, _ := store.LoginFailures(ctx, "other"); count != 0 {
+			t.Fatalf("счетчики пользователей не независимы: %d", count)
+		}
+
+		// Окно отсчитывается от первой неудачи, а не продлевается каждой следующей
+		advance(60 * time.Millisecond)
+		count, retry, err := store.RecordLoginFailure(ctx, "user123", window)
+		if err != nil || count != 4 || retry > 40*time.Millisecond {
+			t.Fatalf("после 60ms: (%d, %s, %v), ожидалось 4 попытки и не больше 40ms до сброса", count, retry, err)
+		}
+		if got, retry, err := store.LoginFailures(ctx, "user123"); err != nil || got != 4 || retry <= 0 {
+			t.Fatalf("LoginFailures: (%d, %s, %v), ожидалось 4", got, retry, err)
+		}
+
+		advance(50 * time.Millisecond)
+		if count, _, _ := store.LoginFailures(ctx, "user123"); count != 0 {
+			t.Fatalf("счетчик не сброшен по окончании окна: %d", count)
+		}
+		if count, retry, _ := store.RecordLoginFailure(ctx, "user123", window); count != 1 || retry <= 60*time.Millisecond {
+			t.Fatalf("новое окно: (%d, %s), ожидалась первая попытка с полным окном", count, retry)
+		}
+	})
+
+	t.Run("reset login failures", func(t *testing.T) {
+		store, _ := newStore(t)
+		store.RecordLoginFailure(ctx, "user123", time.Minute)
+		store.RecordLoginFailure(ctx, "user123", time.Minute)
+		if err := store.ResetLoginFailures(ctx, "user123"); err != nil {
+			t.Fatalf("ResetLoginFailures: %v", err)
+		}
+		if count, _, _ := store.LoginFailures(ctx, "user123"); count != 0 {
+			t.Fatalf("после сброса %d попыток", count)
+		}
+		if count, _, _ := store.RecordLoginFailure(ctx, "user123", time.Minute); count != 1 {
+			t.Fatalf("после сброса счет начался с %d", count)
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		store, _ := newStore(t)
+		first, second := make(chan string, 1), make(chan string, 1)
+		store.Subscribe(func(username string) { first <- username })
+		store.Subscribe(func(username string) { second <- username })
+
+		if err := store.Invalidate(ctx, "user123"); err != nil {
+			t.Fatalf("Invalidate: %v", err)
+		}
+		expectInvalidation(t, first, "user123")
+		expectInvalidation(t, second, "user123")
+	})
+}
+
+// checkRevoked сравнивает результат проверки отзыва с ожидаемым
+func checkRevoked(t *testing.T, isRevoked func(context.Context, string) (bool, error), id string, want bool) {
+	t.Helper()
+	revoked, err := isRevoked(context.Background(), id)
+	if err != nil {
+		t.Fatalf("проверка отзыва %s: %v", id, err)
+	}
+	if revoked != want {
+		t.Fatalf("%s отозван: %v, ожидалось %v", id, revoked, want)
+	}
+}
+
+// expectInvalidation ждет сообщение об изменении данных пользователя
+func expectInvalidation(t *testing.T, received <-chan string, want string) {
+	t.Helper()
+	select {
+	case username := <-received:
+		if username != want {
+			t.Fatalf("сообщение для %q, ожидалось %q", username, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("сообщение для %q не получено", want)
+	}
+}
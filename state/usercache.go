@@ -0,0 +1,97 @@
+// Файл: state/usercache.go
+package state
+
+import (
+	"sync"
+	"time"
+
+	"auth-service/models"
+)
+
+// cachedUser - данные пользователя и момент их получения из API базы данных
+type cachedUser struct {
+	user    models.UserData
+	fetched time.Time
+}
+
+// UserCache хранит ответы API базы данных в памяти реплики. Данные пользователя
+// (включая хеш пароля) не покидают процесс, а согласованность между репликами
+// обеспечивается сообщениями Store.Invalidate.
+type UserCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedUser
+	size    int
+	// generation увеличивается при каждом Delete. Данные, запрошенные из БД до удаления,
+	// могли устареть, поэтому Set с более ранним поколением отбрасывается.
+	generation uint64
+}
+
+// NewUserCache создает кеш не более чем на size пользователей
+func NewUserCache(size int) *UserCache {
+	return &UserCache{entries: make(map[string]cachedUser), size: size}
+}
+
+// Get возвращает копию данных пользователя, полученных не ранее ttl назад
+func (c *UserCache) Get(username string, ttl time.Duration) (*models.UserData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[username]
+	if !ok || time.Since(entry.fetched) >= ttl {
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+// Generation возвращает текущее поколение кеша. Его нужно получить до запроса
+// данных пользователя из БД и передать в Set.
+func (c *UserCache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Set сохраняет данные пользователя, запрошенные в поколении generation. Если с тех пор
+// вызывался Delete, данные могли устареть и не сохраняются. При заполненном кеше
+// сначала удаляются устаревшие записи, а если их нет - произвольная.
+func (c *UserCache) Set(username string, user *models.UserData, ttl time.Duration, generation uint64) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if _, ok := c.entries[username]; !ok && len(c.entries) >= c.size {
+		for key, entry := range c.entries {
+			if now.Sub(entry.fetched) >= ttl {
+				delete(c.entries, key)
+			}
+		}
+		for key := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, key)
+		}
+	}
+	c.entries[username] = cachedUser{user: *user, fetched: now}
+}
+
+// Delete удаляет пользователя из кеша
+func (c *UserCache) Delete(username string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, username)
+	c.generation++
+}
+
+// Len возвращает число пользователей в кеше
+func (c *UserCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
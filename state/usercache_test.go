@@ -0,0 +1,72 @@
+// Файл: state/usercache_test.go
+package state
+
+import (
+	"testing"
+	"time"
+
+	"auth-service/models"
+)
+
+func TestUserCacheTTL(t *testing.T) {
+	cache := NewUserCache(10)
+	cache.Set("user123", &models.UserData{Login: "user123", AgencyID: 42}, time.Minute, cache.Generation())
+
+	user, ok := cache.Get("user123", time.Minute)
+	if !ok || user.AgencyID != 42 {
+		t.Fatalf("Get: (%+v, %v), ожидались данные из кеша", user, ok)
+	}
+	// Возвращается копия: изменения не попадают в кеш
+	user.AgencyID = 7
+	if user, _ := cache.Get("user123", time.Minute); user.AgencyID != 42 {
+		t.Fatalf("кеш изменен через возвращенную копию: %d", user.AgencyID)
+	}
+	if _, ok := cache.Get("user123", 0); ok {
+		t.Fatal("получены данные старше ttl")
+	}
+}
+
+func TestUserCacheEviction(t *testing.T) {
+	cache := NewUserCache(2)
+	cache.Set("old", &models.UserData{Login: "old"}, time.Minute, cache.Generation())
+	time.Sleep(50 * time.Millisecond)
+	cache.Set("fresh", &models.UserData{Login: "fresh"}, time.Minute, cache.Generation())
+
+	// Устаревшая запись вытесняется первой
+	cache.Set("new", &models.UserData{Login: "new"}, 25*time.Millisecond, cache.Generation())
+	if cache.Len() != 2 {
+		t.Fatalf("в кеше %d записей, ожидалось 2", cache.Len())
+	}
+	if _, ok := cache.Get("old", time.Minute); ok {
+		t.Fatal("устаревшая запись не вытеснена")
+	}
+	if _, ok := cache.Get("fresh", time.Minute); !ok {
+		t.Fatal("вытеснена свежая запись")
+	}
+
+	// Обновление существующей записи ничего не вытесняет
+	cache.Set("fresh", &models.UserData{Login: "fresh"}, time.Minute, cache.Generation())
+	if cache.Len() != 2 {
+		t.Fatalf("в кеше %d записей, ожидалось 2", cache.Len())
+	}
+}
+
+func TestUserCacheSetAfterDelete(t *testing.T) {
+	cache := NewUserCache(10)
+	cache.Set("user123", &models.UserData{Login: "user123", JWTToken: "old"}, time.Minute, cache.Generation())
+
+	// Запрос к БД начался до смены токена, а ответ пришел после инвалидации
+	generation := cache.Generation()
+	stale := &models.UserData{Login: "user123", JWTToken: "old"}
+	cache.Delete("user123")
+	cache.Set("user123", stale, time.Minute, generation)
+	if _, ok := cache.Get("user123", time.Minute); ok {
+		t.Fatal("сохранены данные, запрошенные до инвалидации")
+	}
+
+	// Данные, запрошенные после инвалидации, кешируются
+	cache.Set("user123", &models.UserData{Login: "user123", JWTToken: "new"}, time.Minute, cache.Generation())
+	if user, ok := cache.Get("user123", time.Minute); !ok || user.JWTToken != "new" {
+		t.Fatalf("Get: (%+v, %v), ожидались новые данные", user, ok)
+	}
+}
@@ -0,0 +1,219 @@
+// Файл: tlsconfig/tlsconfig.go
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"auth-service/config"
+	"auth-service/logger"
+)
+
+// TLS версии, допустимые в min_version
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// cipherSuiteID возвращает идентификатор набора шифров по его имени IANA.
+// Небезопасные наборы не поддерживаются.
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// Reloader хранит сертификат сервера и CA клиентов и перечитывает их при изменении файлов
+type Reloader struct {
+	cfg config.TLSConfig
+	log *logger.ColorfulLogger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// New загружает сертификат, ключ и CA клиентов из файлов, указанных в конфигурации
+func New(cfg config.TLSConfig, log *logger.ColorfulLogger) (*Reloader, error) {
+	r := &Reloader{cfg: cfg, log: log}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load читает файлы сертификатов и атомарно заменяет текущие
+func (r *Reloader) load() error {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки сертификата TLS: %w", err)
+	}
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("ошибка разбора сертификата TLS: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		data, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения CA клиентских сертификатов: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("в файле %s нет сертификатов CA в формате PEM", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// files возвращает список отслеживаемых файлов
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// statFiles возвращает время изменения отслеживаемых файлов
+func (r *Reloader) statFiles() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка доступа к файлу TLS: %w", err)
+		}
+		modTimes[path] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// changed проверяет, изменился ли хотя бы один отслеживаемый файл
+func (r *Reloader) changed() bool {
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for path, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch с интервалом reload_interval проверяет файлы сертификатов и перечитывает их при изменении.
+// Если новые файлы некорректны (например, записаны не полностью), сохраняются прежние.
+func (r *Reloader) Watch(stop <-chan struct{}) {
+	if r.cfg.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.ReloadInterval.Std())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+		if err := r.load(); err != nil {
+			r.log.Error("Сертификат TLS изменен, но не загружен, используется прежний: %v", err)
+			continue
+		}
+		r.log.Info("Сертификат TLS перезагружен, действует до %s", r.Certificate().Leaf.NotAfter.Format(time.RFC3339))
+	}
+}
+
+// Certificate возвращает текущий сертификат сервера
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// Check проверяет, что текущий сертификат сервера действителен
+func (r *Reloader) Check() error {
+	leaf := r.Certificate().Leaf
+	now := time.Now()
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("сертификат TLS начнет действовать с %s", leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("срок действия сертификата TLS истек %s", leaf.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// TLSConfig возвращает конфигурацию TLS сервера, которая при каждом подключении
+// использует текущие сертификат и CA клиентов
+func (r *Reloader) TLSConfig() (*tls.Config, error) {
+	base := &tls.Config{
+		MinVersion: versions[r.cfg.MinVersion],
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		},
+	}
+
+	for _, name := range r.cfg.CipherSuites {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			return nil, fmt.Errorf("неизвестный набор шифров %q", name)
+		}
+		base.CipherSuites = append(base.CipherSuites, id)
+	}
+
+	switch r.cfg.ClientAuth {
+	case config.ClientAuthNone, "":
+		return base, nil
+	case config.ClientAuthRequest:
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case config.ClientAuthRequire:
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("неизвестный режим проверки клиентских сертификатов %q", r.cfg.ClientAuth)
+	}
+
+	if r.cfg.ClientCAFile == "" {
+		return nil, errors.New("для проверки клиентских сертификатов нужен client_ca_file")
+	}
+
+	// CA клиентов подставляются при каждом подключении, чтобы учитывать перезагрузку файла
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		r.mu.RLock()
+		cfg.ClientCAs = r.clientCAs
+		r.mu.RUnlock()
+		return cfg, nil
+	}
+	return base, nil
+}
@@ -1,21 +0,0 @@
-// Файл: utils/password.go
-package utils
-
-import (
-	"golang.org/x/crypto/bcrypt"
-)
-
-// VerifyPassword сравнивает пароль в открытом виде с хешированным
-func VerifyPassword(plainPassword, hashedPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
-	return err == nil
-}
-
-// HashPassword генерирует хеш пароля
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(bytes), nil
-}
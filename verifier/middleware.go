@@ -0,0 +1,109 @@
+// Файл: verifier/middleware.go
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey - ключ данных токена в контексте запроса
+type contextKey struct{}
+
+// NewContext возвращает контекст с данными аутентифицированного пользователя
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext возвращает данные токена, сохраненные middleware
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// Username возвращает имя аутентифицированного пользователя или пустую строку
+func Username(ctx context.Context) string {
+	if claims, ok := FromContext(ctx); ok {
+		return claims.Username
+	}
+	return ""
+}
+
+// AgencyID возвращает ID агентства аутентифицированного пользователя
+func AgencyID(ctx context.Context) (int, bool) {
+	if claims, ok := FromContext(ctx); ok {
+		return claims.AgencyID, true
+	}
+	return 0, false
+}
+
+// Middleware проверяет токен из заголовка Authorization для net/http обработчиков
+// и сохраняет его данные в контексте запроса
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := v.Verify(r.Context(), bearerToken(r))
+		if err != nil {
+			status, challenge, message := errorResponse(err)
+			if challenge != "" {
+				w.Header().Set("WWW-Authenticate", challenge)
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": message})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+	})
+}
+
+// GinMiddleware проверяет токен из заголовка Authorization для Gin обработчиков.
+// Данные токена доступны через FromContext(c.Request.Context()), а также
+// по ключам "username" и "agencyID", как в middleware сервиса аутентификации.
+func (v *Verifier) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := v.Verify(c.Request.Context(), bearerToken(c.Request))
+		if err != nil {
+			status, challenge, message := errorResponse(err)
+			if challenge != "" {
+				c.Header("WWW-Authenticate", challenge)
+			}
+			c.AbortWithStatusJSON(status, gin.H{"error": message})
+			return
+		}
+
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), claims))
+		c.Set("username", claims.Username)
+		c.Set("agencyID", claims.AgencyID)
+		c.Next()
+	}
+}
+
+// bearerToken извлекает токен из заголовка Authorization; схема Bearer распознается без учета регистра
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// errorResponse выбирает код ответа и заголовок WWW-Authenticate (RFC 6750) для ошибки проверки;
+// пустой challenge - заголовок не передается
+func errorResponse(err error) (status int, challenge, message string) {
+	switch {
+	case errors.Is(err, ErrNoToken):
+		return http.StatusUnauthorized, "Bearer", "Отсутствуют учетные данные"
+	case errors.Is(err, ErrInvalidToken):
+		return http.StatusUnauthorized, `Bearer error="invalid_token"`, "Недействительный токен"
+	case errors.Is(err, ErrAccountInactive):
+		return http.StatusForbidden, "", "Учетная запись отключена или требует смены пароля"
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests, "", "Слишком много запросов к сервису аутентификации"
+	default:
+		return http.StatusServiceUnavailable, "Bearer", "Сервис аутентификации недоступен"
+	}
+}
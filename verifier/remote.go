@@ -0,0 +1,105 @@
+// Файл: verifier/remote.go
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"auth-service/jwks"
+	"auth-service/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxResponseSize ограничивает размер ответов сервиса аутентификации
+const maxResponseSize = 1 << 20
+
+// Коды ответов /token/verify для неактивных учетных записей
+const (
+	codeAccountDisabled       = "account_disabled"
+	codePasswordResetRequired = "password_reset_required"
+)
+
+// fetchJWKS загружает набор открытых ключей
+func (v *Verifier) fetchJWKS(ctx context.Context) (jwks.Set, error) {
+	var set jwks.Set
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return set, fmt.Errorf("ошибка создания запроса JWKS: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return set, fmt.Errorf("%w: ошибка загрузки JWKS: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return set, fmt.Errorf("%w: JWKS вернул ошибку: %d", ErrUnavailable, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&set); err != nil {
+		return set, fmt.Errorf("ошибка декодирования JWKS: %w", err)
+	}
+	return set, nil
+}
+
+// introspect проверяет токен запросом к /token/verify сервиса аутентификации
+func (v *Verifier) introspect(ctx context.Context, token string) (*Claims, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.cfg.IntrospectionURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+	body := io.LimitReader(resp.Body, maxResponseSize)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, introspectionError(resp.StatusCode, body)
+	}
+
+	var result struct {
+		Valid    bool   `json:"valid"`
+		Username string `json:"username"`
+		AgencyID int    `json:"agency_id"`
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: ошибка декодирования ответа: %v", ErrUnavailable, err)
+	}
+	if !result.Valid || result.Username == "" {
+		return nil, ErrInvalidToken
+	}
+	return &Claims{Username: result.Username, AgencyID: result.AgencyID}, nil
+}
+
+// introspectionError выбирает ошибку по коду ответа /token/verify и машиночитаемому коду ошибки
+func introspectionError(status int, body io.Reader) error {
+	var errResp models.ErrorResponse
+	json.NewDecoder(body).Decode(&errResp)
+
+	switch status {
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		return fmt.Errorf("%w: %s", ErrInvalidToken, errResp.Error)
+	case http.StatusForbidden:
+		if errResp.Code == codeAccountDisabled || errResp.Code == codePasswordResetRequired {
+			return fmt.Errorf("%w (%s): %s", ErrAccountInactive, errResp.Code, errResp.Error)
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidToken, errResp.Error)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %s", ErrRateLimited, errResp.Error)
+	default:
+		return fmt.Errorf("%w: ответ %d", ErrUnavailable, status)
+	}
+}
@@ -0,0 +1,88 @@
+// Файл: verifier/remote_test.go
+package verifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIntrospectStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    error // nil - токен принят
+		wantStatus int   // Код ответа Middleware
+	}{
+		{"valid", http.StatusOK, `{"valid":true,"username":"user123","agency_id":42}`, nil, http.StatusOK},
+		{"not valid", http.StatusOK, `{"valid":false}`, ErrInvalidToken, http.StatusUnauthorized},
+		{"malformed", http.StatusBadRequest, `{"code":"malformed_credentials","error":"bad"}`, ErrInvalidToken, http.StatusUnauthorized},
+		{"expired", http.StatusUnauthorized, `{"code":"token_expired","error":"expired"}`, ErrInvalidToken, http.StatusUnauthorized},
+		{"revoked", http.StatusUnauthorized, `{"code":"token_revoked","error":"revoked"}`, ErrInvalidToken, http.StatusUnauthorized},
+		{"disabled", http.StatusForbidden, `{"code":"account_disabled","error":"disabled"}`, ErrAccountInactive, http.StatusForbidden},
+		{"reset required", http.StatusForbidden, `{"code":"password_reset_required","error":"reset"}`, ErrAccountInactive, http.StatusForbidden},
+		{"other forbidden", http.StatusForbidden, `{"code":"csrf_failed","error":"csrf"}`, ErrInvalidToken, http.StatusUnauthorized},
+		{"rate limited", http.StatusTooManyRequests, `{"code":"rate_limited","error":"slow down"}`, ErrRateLimited, http.StatusTooManyRequests},
+		{"internal error", http.StatusInternalServerError, `{"code":"internal_error"}`, ErrUnavailable, http.StatusServiceUnavailable},
+		{"backend unavailable", http.StatusServiceUnavailable, `{"code":"backend_unavailable"}`, ErrUnavailable, http.StatusServiceUnavailable},
+		{"not problem json", http.StatusForbidden, `forbidden`, ErrInvalidToken, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("Authorization %q, ожидался Bearer token", r.Header.Get("Authorization"))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			v, err := New(Config{IntrospectionURL: server.URL})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			defer v.Close()
+
+			claims, err := v.Verify(context.Background(), "token")
+			if tt.wantErr == nil {
+				if err != nil || claims.Username != "user123" || claims.AgencyID != 42 {
+					t.Fatalf("получено (%+v, %v), ожидался принятый токен", claims, err)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(recorder, req)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Middleware: статус %d, ожидался %d", recorder.Code, tt.wantStatus)
+			}
+			if (tt.wantStatus == http.StatusForbidden || tt.wantStatus == http.StatusTooManyRequests) && recorder.Header().Get("WWW-Authenticate") != "" {
+				t.Errorf("WWW-Authenticate %q в ответе %d", recorder.Header().Get("WWW-Authenticate"), recorder.Code)
+			}
+		})
+	}
+}
+
+func TestIntrospectNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	v, err := New(Config{IntrospectionURL: server.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer v.Close()
+
+	if _, err := v.Verify(context.Background(), "token"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("ошибка %v, ожидалась ErrUnavailable", err)
+	}
+}
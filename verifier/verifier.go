@@ -0,0 +1,306 @@
+// Файл: verifier/verifier.go
+
+// Package verifier проверяет токены сервиса аутентификации в других Go сервисах:
+// локально по открытым ключам из JWKS и, если это невозможно, через /token/verify.
+package verifier
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"auth-service/jwks"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Значения по умолчанию
+const (
+	defaultRefreshInterval = 5 * time.Minute
+	defaultHTTPTimeout     = 10 * time.Second
+
+	// minRefreshInterval ограничивает внеочередные загрузки JWKS при токенах с неизвестным kid
+	minRefreshInterval = 30 * time.Second
+)
+
+// Ошибки проверки токена
+var (
+	ErrNoToken      = errors.New("отсутствует токен доступа")
+	ErrInvalidToken = errors.New("недействительный токен")
+	ErrUnavailable  = errors.New("сервис аутентификации недоступен")
+
+	// ErrAccountInactive означает, что токен действителен, но учетная запись отключена
+	// или требует смены пароля; возвращается только при удаленной проверке
+	ErrAccountInactive = errors.New("учетная запись неактивна")
+
+	// ErrRateLimited означает, что сервис аутентификации отклонил проверку из-за ограничения частоты
+	ErrRateLimited = errors.New("превышен лимит запросов к сервису аутентификации")
+)
+
+// agencyClaim - имя claim с ID агентства
+const agencyClaim = "ngy"
+
+// errNoKey означает, что токен нельзя проверить локально
+var errNoKey = errors.New("ключ проверки подписи не найден")
+
+// Claims представляет данные, хранящиеся в токене сервиса аутентификации
+type Claims struct {
+	Username string `json:"sub"`
+	AgencyID int    `json:"ngy"`
+
+	// Заполняется только в токенах обновления, которые нельзя использовать как токены доступа
+	TokenType string `json:"token_type,omitempty"`
+
+	jwt.RegisteredClaims
+}
+
+// Config содержит настройки проверки токенов
+type Config struct {
+	// Адрес JWKS, например https://auth.example.com/.well-known/jwks.json; пусто - только удаленная проверка
+	JWKSURL string
+
+	// Адрес /token/verify для токенов, которые нельзя проверить локально (HS256 или неизвестный ключ);
+	// пусто - удаленная проверка отключена
+	IntrospectionURL string
+
+	// Период фонового обновления JWKS; по умолчанию 5 минут
+	RefreshInterval time.Duration
+
+	// Допустимое расхождение часов при проверке срока действия
+	Leeway time.Duration
+
+	// HTTP клиент для запросов к сервису аутентификации; по умолчанию с таймаутом 10 секунд
+	HTTPClient *http.Client
+
+	// Получает ошибки фонового обновления JWKS; по умолчанию ошибки игнорируются
+	ErrorHandler func(error)
+}
+
+// verificationKey - открытый ключ из JWKS
+type verificationKey struct {
+	alg string
+	pub crypto.PublicKey
+}
+
+// Verifier проверяет токены доступа. Методы безопасны для параллельного использования.
+//
+// Локальная проверка не узнает о выходе пользователя до истечения токена; если это важно,
+// задайте только IntrospectionURL, и каждый токен будет проверяться сервисом аутентификации.
+type Verifier struct {
+	cfg Config
+
+	mu          sync.RWMutex
+	keys        map[string]verificationKey
+	refreshedAt time.Time
+
+	refreshMu sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// New создает Verifier и загружает JWKS. Если JWKS недоступен, но задан IntrospectionURL,
+// ошибка передается в ErrorHandler, а токены проверяются удаленно до успешного обновления.
+func New(cfg Config) (*Verifier, error) {
+	if cfg.JWKSURL == "" && cfg.IntrospectionURL == "" {
+		return nil, errors.New("нужен хотя бы один из адресов JWKSURL и IntrospectionURL")
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	v := &Verifier{cfg: cfg, keys: map[string]verificationKey{}, stop: make(chan struct{}), done: make(chan struct{})}
+	if cfg.JWKSURL == "" {
+		close(v.done)
+		return v, nil
+	}
+
+	if err := v.refresh(context.Background()); err != nil {
+		if cfg.IntrospectionURL == "" {
+			return nil, err
+		}
+		v.reportError(err)
+	}
+	go v.refreshLoop()
+	return v, nil
+}
+
+// Close останавливает фоновое обновление JWKS
+func (v *Verifier) Close() {
+	select {
+	case <-v.stop:
+	default:
+		close(v.stop)
+	}
+	<-v.done
+}
+
+// Verify проверяет токен доступа и возвращает его данные. Ошибки оборачивают ErrNoToken,
+// ErrInvalidToken, ErrAccountInactive, ErrRateLimited или ErrUnavailable.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+
+	if v.cfg.JWKSURL != "" {
+		claims, err := v.verifyLocal(ctx, token)
+		if !errors.Is(err, errNoKey) {
+			return claims, err
+		}
+		if v.cfg.IntrospectionURL == "" {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+	}
+	return v.introspect(ctx, token)
+}
+
+// verifyLocal проверяет подпись токена открытым ключом из JWKS
+func (v *Verifier) verifyLocal(ctx context.Context, token string) (*Claims, error) {
+	// Исходные claims нужны, чтобы отличить отсутствующий ngy от нулевого ID агентства
+	unverified, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	kid, _ := unverified.Header["kid"].(string)
+	if kid == "" {
+		// Токены с общим секретом (HS256) не содержат kid и проверяются удаленно
+		return nil, errNoKey
+	}
+
+	key, ok := v.key(kid)
+	if !ok {
+		// Ключ мог появиться после последнего обновления JWKS
+		if err := v.refreshIfStale(ctx); err != nil {
+			v.reportError(err)
+		}
+		if key, ok = v.key(kid); !ok {
+			return nil, errNoKey
+		}
+	}
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() != key.alg {
+			return nil, errors.New("алгоритм подписи не соответствует ключу")
+		}
+		return key.pub, nil
+	},
+		jwt.WithValidMethods([]string{jwks.AlgorithmRS256, jwks.AlgorithmES256, jwks.AlgorithmEdDSA}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.cfg.Leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	switch {
+	case claims.TokenType != "":
+		return nil, fmt.Errorf("%w: неверный тип токена", ErrInvalidToken)
+	case claims.Username == "":
+		return nil, fmt.Errorf("%w: отсутствует имя пользователя", ErrInvalidToken)
+	case !hasClaim(unverified.Claims, agencyClaim):
+		return nil, fmt.Errorf("%w: отсутствует ID агентства", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// HasAgencyID сообщает, содержит ли токен claim ngy: при разборе в Claims отсутствующий
+// claim дает AgencyID 0, неотличимый от агентства 0. Подпись не проверяется,
+// поэтому функция вызывается после проверки токена.
+func HasAgencyID(token string) bool {
+	unverified, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	return err == nil && hasClaim(unverified.Claims, agencyClaim)
+}
+
+// hasClaim сообщает, содержит ли токен claim с непустым значением
+func hasClaim(claims jwt.Claims, name string) bool {
+	raw, ok := claims.(jwt.MapClaims)
+	return ok && raw[name] != nil
+}
+
+// key возвращает ключ проверки подписи по идентификатору
+func (v *Verifier) key(kid string) (verificationKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refreshLoop периодически обновляет JWKS до вызова Close
+func (v *Verifier) refreshLoop() {
+	defer close(v.done)
+
+	ticker := time.NewTicker(v.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-v.stop:
+			return
+		case <-ticker.C:
+			if err := v.refresh(context.Background()); err != nil {
+				v.reportError(err)
+			}
+		}
+	}
+}
+
+// refreshIfStale обновляет JWKS, если последнее обновление было не менее minRefreshInterval назад
+func (v *Verifier) refreshIfStale(ctx context.Context) error {
+	v.mu.RLock()
+	stale := time.Since(v.refreshedAt) >= minRefreshInterval
+	v.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+	return v.refresh(ctx)
+}
+
+// refresh загружает JWKS и атомарно заменяет набор ключей
+func (v *Verifier) refresh(ctx context.Context) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	set, err := v.fetchJWKS(ctx)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// Неудачная попытка тоже учитывается, чтобы недоступный JWKS не запрашивался на каждый токен
+	v.refreshedAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+	var errs []error
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		pub, err := key.PublicKey()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ключ %q: %w", key.Kid, err))
+			continue
+		}
+		alg, err := jwks.Algorithm(pub)
+		if err != nil || (key.Alg != "" && key.Alg != alg) {
+			errs = append(errs, fmt.Errorf("ключ %q: неподдерживаемый алгоритм %q", key.Kid, key.Alg))
+			continue
+		}
+		keys[key.Kid] = verificationKey{alg: alg, pub: pub}
+	}
+	v.keys = keys
+	return errors.Join(errs...)
+}
+
+// reportError передает ошибку в ErrorHandler
+func (v *Verifier) reportError(err error) {
+	if v.cfg.ErrorHandler != nil {
+		v.cfg.ErrorHandler(err)
+	}
+}
@@ -0,0 +1,33 @@
+// Файл: verifier/verifier_test.go
+package verifier
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestHasAgencyID(t *testing.T) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	token := func(payload string) string {
+		return header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2ln"
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{"agency 42", token(`{"sub":"user123","ngy":42}`), true},
+		{"agency 0", token(`{"sub":"user123","ngy":0}`), true},
+		{"missing", token(`{"sub":"user123"}`), false},
+		{"null", token(`{"sub":"user123","ngy":null}`), false},
+		{"not a token", "abc", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasAgencyID(tt.token); got != tt.want {
+				t.Errorf("HasAgencyID = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}